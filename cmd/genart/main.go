@@ -7,29 +7,50 @@ import (
 	"encoding/json"
 	"flag"
 	"fmt"
+	"image"
 	"image/png"
+	"io"
+	"math"
 	"math/rand"
 	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/tabwriter"
 
 	"genart/internal/anim"
+	"genart/internal/colorize"
 	"genart/internal/config"
 	"genart/internal/core"
-	"genart/internal/engines/blackhole"
-	"genart/internal/engines/contourlines"
-	"genart/internal/engines/flowfield"
-	"genart/internal/engines/perlinpearls"
-	"genart/internal/engines/swirl"
-	"genart/internal/engines/flow"
-	"genart/internal/engines/strata"
+	_ "genart/internal/engines/all"
+	"genart/internal/noise"
 	"genart/internal/palette"
+	"genart/internal/plot"
+	"genart/internal/registry"
 	"genart/internal/render"
 )
 
 func main() {
 	// --- Flags ---
 	configFlag := flag.String("config", "", "JSON config string or path to .json file")
+	describeFlag := flag.String("describe", "", "print the parameters accepted by an engine and exit")
+	listFlag := flag.Bool("list", false, "list available engines, palettes and renderers and exit")
 	flag.Parse()
 
+	if *listFlag {
+		list(os.Stdout)
+		return
+	}
+
+	if *describeFlag != "" {
+		eng, err := registry.Engines.Lookup(*describeFlag)
+		if err != nil {
+			exitErr(err.Error())
+		}
+		describe(os.Stdout, eng)
+		return
+	}
+
 	if *configFlag == "" {
 		exitErr("you must pass -config (JSON string or file)")
 	}
@@ -40,40 +61,42 @@ func main() {
 		exitErr("failed to load config: " + err.Error())
 	}
 
-	// --- Registry ---
-	engines := map[string]core.Engine{
-		"flowfield":    flowfield.Engine{},
-		"contourlines": contourlines.Engine{},
-		"blackhole":    blackhole.Engine{},
-		"perlinpearls": perlinpearls.Engine{},
-		"swirl":        swirl.Engine{},
-		"flow":         flow.Engine{},
-		"strata":       strata.Engine{},
+	eng, err := registry.Engines.Lookup(cfg.Engine)
+	if err != nil {
+		exitErr(err.Error())
 	}
 
-	eng, ok := engines[cfg.Engine]
-	if !ok {
-		exitErr(fmt.Sprintf("invalid engine %q", cfg.Engine))
+	// --- Validate params and fill in defaults ---
+	cfg.Params, err = core.ResolveNames(eng, cfg.Params, cfg.ParamNames)
+	if err != nil {
+		exitErr("invalid params: " + err.Error())
+	}
+	cfg.Params, err = core.ResolveParams(eng, cfg.Params)
+	if err != nil {
+		exitErr("invalid params: " + err.Error())
+	}
+
+	if _, ok := eng.(core.FieldUser); cfg.Field != nil && !ok {
+		exitErr(fmt.Sprintf("engine %q does not accept a custom field", eng.Name()))
 	}
 
 	// --- Build palette ---
-	var colors []core.RGBA
-	switch cfg.Palette.Type {
-	case "mono":
-		colors = palette.Monochrome(cfg.Palette.Base, cfg.Palette.N)
-	case "split-complementary":
-		colors = palette.SplitComplementary(cfg.Palette.Base, cfg.Palette.N)
-	case "analogous":
-		colors = palette.Analogous(cfg.Palette.Base, cfg.Palette.N)
-	default:
-		exitErr(fmt.Sprintf("unknown palette type %q", cfg.Palette.Type))
+	colors, ramp, err := palette.ResolveRamp(&cfg.Palette)
+	if err != nil {
+		exitErr(err.Error())
 	}
 
 	// --- Print root seed ---
 	fmt.Fprintf(os.Stderr, "Root seed: %d\n", cfg.Seed)
 
-	// --- Run animation or static render ---
+	// --- Run animation, plot export or static render ---
 	if cfg.Animation != nil {
+		if cfg.Plot != nil {
+			exitErr("plot export does not support animations")
+		}
+		if cfg.Render.TilePreview != "" {
+			exitErr("tile_preview does not support animations")
+		}
 		if err := anim.Run(cfg, eng); err != nil {
 			exitErr("animation failed: " + err.Error())
 		}
@@ -82,21 +105,30 @@ func main() {
 		subSeed := deriveSeed(cfg.Seed, eng.Name())
 		rng := rand.New(rand.NewSource(subSeed))
 
-		scene, err := eng.Generate(context.Background(), rng, cfg.Params, colors)
+		ctx := context.Background()
+		field, err := noise.Resolve(cfg.Field, deriveSeed(cfg.Seed, "field"), float64(cfg.Width)/float64(cfg.Height))
 		if err != nil {
-			exitErr("engine failed: " + err.Error())
+			exitErr("field: " + err.Error())
+		}
+		if field != nil {
+			ctx = noise.WithField(ctx, field)
+		}
+		if ramp != nil {
+			ctx = palette.WithRamp(ctx, ramp)
 		}
 
-		img, err := (render.GG{}).Render(scene, core.RenderConfig{
-			Width:       cfg.Width,
-			Height:      cfg.Height,
-			Background:  cfg.Background,
-			Margin:      cfg.Render.Margin,
-			Supersample: cfg.Render.Supersample,
-			Palette:     colors,
-		})
+		scene, err := eng.Generate(ctx, rng, cfg.Params, colors)
 		if err != nil {
-			exitErr("render failed: " + err.Error())
+			exitErr("engine failed: " + err.Error())
+		}
+
+		if cfg.Colorize != nil {
+			crng := rand.New(rand.NewSource(deriveSeed(cfg.Seed, "colorize")))
+			s, err := colorize.New(*cfg.Colorize, colors, ramp, crng)
+			if err != nil {
+				exitErr("colorize failed: " + err.Error())
+			}
+			colorize.Apply(&scene, s)
 		}
 
 		f, err := os.Create(cfg.Out)
@@ -104,8 +136,15 @@ func main() {
 			exitErr("failed to create file: " + err.Error())
 		}
 		defer f.Close()
-		if err := png.Encode(f, img); err != nil {
-			exitErr("failed to encode PNG: " + err.Error())
+
+		if cfg.Plot != nil {
+			stats, err := plot.Export(f, scene, cfg)
+			if err != nil {
+				exitErr("plot export failed: " + err.Error())
+			}
+			fmt.Fprintf(os.Stderr, "Plot: %s\n", stats)
+		} else {
+			renderStatic(f, cfg, scene, colors)
 		}
 	}
 
@@ -117,6 +156,135 @@ func main() {
 
 // --- Helpers ---
 
+// renderStatic renders scene with the configured backend and writes
+// it to w as SVG or PNG depending on cfg.Out.
+func renderStatic(w io.Writer, cfg *config.Config, scene core.Scene, colors []core.RGBA) {
+	rend, err := selectRenderer(cfg)
+	if err != nil {
+		exitErr(err.Error())
+	}
+
+	rcfg := core.RenderConfig{
+		Width:       cfg.Width,
+		Height:      cfg.Height,
+		Background:  cfg.Background,
+		Margin:      cfg.Render.Margin,
+		Supersample: cfg.Render.Supersample,
+		Filter:      cfg.Render.Filter,
+		Tile:        cfg.Render.Tile,
+		Palette:     colors,
+	}
+
+	if strings.EqualFold(filepath.Ext(cfg.Out), ".svg") {
+		vr, ok := rend.(core.VectorRenderer)
+		if !ok {
+			exitErr(fmt.Sprintf("renderer %q cannot write SVG", rend.Name()))
+		}
+		if err := vr.Encode(w, scene, rcfg); err != nil {
+			exitErr("render failed: " + err.Error())
+		}
+		if cfg.Render.TilePreview != "" {
+			img, err := rend.Render(scene, rcfg)
+			if err != nil {
+				exitErr("render failed: " + err.Error())
+			}
+			writeTilePreview(cfg.Render.TilePreview, img)
+		}
+		return
+	}
+
+	img, err := rend.Render(scene, rcfg)
+	if err != nil {
+		exitErr("render failed: " + err.Error())
+	}
+	if err := png.Encode(w, img); err != nil {
+		exitErr("failed to encode PNG: " + err.Error())
+	}
+	if cfg.Render.TilePreview != "" {
+		writeTilePreview(cfg.Render.TilePreview, img)
+	}
+}
+
+// writeTilePreview writes img repeated 2×2 as a PNG, to check a tile's
+// seams.
+func writeTilePreview(path string, img image.Image) {
+	f, err := os.Create(path)
+	if err != nil {
+		exitErr("failed to create tile preview: " + err.Error())
+	}
+	defer f.Close()
+	if err := png.Encode(f, render.TileImage(img, 2, 2)); err != nil {
+		exitErr("failed to encode tile preview: " + err.Error())
+	}
+}
+
+// list prints every registered engine, palette and renderer.
+func list(w io.Writer) {
+	section := func(title string, names []string, aliases func(string) []string) {
+		fmt.Fprintf(w, "%s:\n", title)
+		for _, n := range names {
+			if a := aliases(n); len(a) > 0 {
+				fmt.Fprintf(w, "  %s (%s)\n", n, strings.Join(a, ", "))
+			} else {
+				fmt.Fprintf(w, "  %s\n", n)
+			}
+		}
+	}
+	section("engines", registry.Engines.Names(), registry.Engines.Aliases)
+	section("palettes", registry.Palettes.Names(), registry.Palettes.Aliases)
+	section("renderers", registry.Renderers.Names(), registry.Renderers.Aliases)
+}
+
+// describe prints an engine's parameter spec as a table.
+func describe(w io.Writer, eng core.Engine) {
+	d, ok := eng.(core.Describer)
+	if !ok {
+		fmt.Fprintf(w, "engine %q does not describe its parameters\n", eng.Name())
+		return
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "PARAM\tTYPE\tDEFAULT\tRANGE\tDESCRIPTION")
+	for _, p := range d.Describe() {
+		def := make([]string, len(p.Default))
+		for i, v := range p.Default {
+			def[i] = strconv.FormatFloat(v, 'g', -1, 64)
+		}
+		rng := fmt.Sprintf("[%s, %s]", fmtBound(p.Min), fmtBound(p.Max))
+		if p.Type == core.ParamEnum {
+			rng = strings.Join(p.Options, "|")
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", p.Name, p.Type, strings.Join(def, ","), rng, p.Description)
+	}
+	tw.Flush()
+}
+
+// fmtBound prints a range bound, showing huge integer limits as infinite.
+func fmtBound(v float64) string {
+	if v >= math.MaxInt32 {
+		return "+Inf"
+	}
+	if v <= math.MinInt32 {
+		return "-Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// selectRenderer resolves cfg.Render.Backend, inferring it from the
+// output extension when unset.
+func selectRenderer(cfg *config.Config) (core.Renderer, error) {
+	backend := cfg.Render.Backend
+	if backend == "" {
+		backend = "gg"
+		if strings.EqualFold(filepath.Ext(cfg.Out), ".svg") {
+			backend = "svg"
+		}
+		cfg.Render.Backend = backend
+	}
+
+	return registry.Renderers.Lookup(backend)
+}
+
 func deriveSeed(root int64, label string) int64 {
 	h := sha256.New()
 	buf := make([]byte, 8)
@@ -7,18 +7,22 @@ import (
 	"encoding/json"
 	"fmt"
 	"image"
-	stdpalette "image/color/palette"
-	"image/draw"
-	"image/gif"
 	"math"
 	"math/rand"
 	"os"
 	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
 
+	"genart/internal/colorize"
 	"genart/internal/config"
 	"genart/internal/core"
+	"genart/internal/noise"
 	"genart/internal/palette"
-	"genart/internal/render"
+	"genart/internal/registry"
+	_ "genart/internal/render"
 )
 
 // FrameLog contains the parameters for a single frame of an animation.
@@ -28,7 +32,8 @@ type FrameLog struct {
 }
 
 // Run executes an animated run based on cfg.Animation.
-// It generates all frames, interpolates params and palette, and writes a GIF.
+// It generates all frames, interpolates params and palette, and writes a GIF,
+// an APNG or a directory of PNG frames depending on the animation format.
 func Run(cfg *config.Config, eng core.Engine) error {
 	anim := cfg.Animation
 	if anim == nil {
@@ -40,50 +45,104 @@ func Run(cfg *config.Config, eng core.Engine) error {
 		return fmt.Errorf("invalid animation frames")
 	}
 
-	images := make([]*image.Paletted, 0, frames)
-	delays := make([]int, 0, frames)
+	if err := resolveFormat(anim, cfg.Out); err != nil {
+		return err
+	}
+	if anim.Format == "gif" {
+		if err := resolveGIF(anim); err != nil {
+			return err
+		}
+	}
+
+	backend := cfg.Render.Backend
+	if backend == "" {
+		backend = "gg"
+	}
+	rend, err := registry.Renderers.Lookup(backend)
+	if err != nil {
+		return err
+	}
+
+	// In loop mode every frame shares one seed and the engine receives
+	// the loop phase instead, so the last frame flows into the first.
+	var looper core.Looper
+	if anim.Loop {
+		l, ok := eng.(core.Looper)
+		if !ok {
+			return fmt.Errorf("engine %q does not support looping animations", eng.Name())
+		}
+		looper = l
+	}
+
+	if _, ok := eng.(core.FieldUser); cfg.Field != nil && !ok {
+		return fmt.Errorf("engine %q does not accept a custom field", eng.Name())
+	}
+	// the custom field is static, so every frame shares it
+	ctx := context.Background()
+	field, err := noise.Resolve(cfg.Field, deriveSeed(cfg.Seed, "field", 0), float64(cfg.Width)/float64(cfg.Height))
+	if err != nil {
+		return err
+	}
+	if field != nil {
+		ctx = noise.WithField(ctx, field)
+	}
+
+	space, err := core.ParseColorSpace(anim.ColorSpace, "")
+	if err != nil {
+		return err
+	}
+	tracks, err := parseTimeline(anim.Vary, anim.Duration, anim.Easing, space)
+	if err != nil {
+		return err
+	}
+	if looper != nil {
+		if err := checkLoop(tracks); err != nil {
+			return err
+		}
+	}
+
+	// Plan every frame up front; this is cheap and keeps the
+	// interpolation sequential and deterministic.
+	jobs := make([]frameJob, 0, frames)
 	frameLogs := make([]FrameLog, 0, frames)
 
 	for frame := 0; frame < frames; frame++ {
-		tRaw := float64(frame) / float64(frames-1)
-		t := ease(tRaw, anim.Easing)
+		// loops never reach t = 1: it is frame 0 again
+		t := float64(frame) / float64(frames-1)
+		if looper != nil {
+			t = float64(frame) / float64(frames)
+		}
 
 		// Copy base params
 		params := make(map[string]float64, len(cfg.Params))
 		for k, v := range cfg.Params {
 			params[k] = v
 		}
+		pc := cfg.Palette
+		bg := cfg.Background
+		var colors []core.RGBA // set when the whole palette is animated
 
-		// Interpolate vary
-		for key, raw := range anim.Vary {
-			switch arr := raw.(type) {
-			case []any:
-				if len(arr) != 2 {
-					continue
-				}
-				// scalar
-				if f0, ok0 := arr[0].(float64); ok0 {
-					if f1, ok1 := arr[1].(float64); ok1 {
-						params[key] = lerp(f0, f1, t)
-						continue
-					}
-				}
-				// color
-				if arr0, ok0 := toFloatSlice(arr[0]); ok0 && len(arr0) >= 3 {
-					if arr1, ok1 := toFloatSlice(arr[1]); ok1 && len(arr1) >= 3 {
-						c0 := core.RGBA{R: arr0[0], G: arr0[1], B: arr0[2], A: 1}
-						c1 := core.RGBA{R: arr1[0], G: arr1[1], B: arr1[2], A: 1}
-						cfg.Palette.Base = core.RGBA{
-							R: lerp(c0.R, c1.R, t),
-							G: lerp(c0.G, c1.G, t),
-							B: lerp(c0.B, c1.B, t),
-							A: lerp(c0.A, c1.A, t),
-						}
-					}
-				}
+		// Evaluate the timeline
+		for _, tr := range tracks {
+			switch tr.key {
+			case keyPalette:
+				colors = tr.colors(t)
+			case keyPaletteBase:
+				pc.Base = tr.colors(t)[0]
+			case keyBackground:
+				bg = tr.colors(t)[0]
+			case keyPaletteN:
+				pc.N = paletteN(tr.at(t)[0])
+			default:
+				params[tr.key] = tr.at(t)[0]
 			}
 		}
 
+		params, err := core.ResolveParams(eng, params)
+		if err != nil {
+			return fmt.Errorf("frame %d: %w", frame, err)
+		}
+
 		if anim.LogFrames {
 			frameLogs = append(frameLogs, FrameLog{
 				Frame:  frame,
@@ -91,43 +150,49 @@ func Run(cfg *config.Config, eng core.Engine) error {
 			})
 		}
 
-		// rebuild palette
-		var colors []core.RGBA
-		switch cfg.Palette.Type {
-		case "mono":
-			colors = palette.Monochrome(cfg.Palette.Base, cfg.Palette.N)
-		default:
-			colors = palette.Monochrome(cfg.Palette.Base, cfg.Palette.N)
+		// rebuild palette; an animated palette list has no ramp and
+		// ramp coloring blends its colors instead
+		var ramp palette.Gradient
+		if colors == nil {
+			colors, ramp, err = palette.ResolveRamp(&pc)
+			if err != nil {
+				return err
+			}
 		}
 
-		// derive seed
-		subSeed := deriveSeed(cfg.Seed, eng.Name(), frame)
-		rng := rand.New(rand.NewSource(subSeed))
-
-		// generate
-		scene, err := eng.Generate(context.Background(), rng, params, colors)
-		if err != nil {
-			return fmt.Errorf("engine failed: %w", err)
-		}
-
-		// render
-		img, err := (render.GG{}).Render(scene, core.RenderConfig{
-			Width:       cfg.Width,
-			Height:      cfg.Height,
-			Background:  cfg.Background,
-			Margin:      cfg.Render.Margin,
-			Supersample: cfg.Render.Supersample,
-			Palette:     colors,
-		})
-		if err != nil {
-			return fmt.Errorf("render failed: %w", err)
+		job := frameJob{
+			frame:     frame,
+			seed:      deriveSeed(cfg.Seed, eng.Name(), frame),
+			params:    params,
+			colors:    colors,
+			ramp:      ramp,
+			bg:        bg,
+			colorSeed: deriveSeed(cfg.Seed, "colorize", frame),
 		}
+		if looper != nil {
+			job.seed = deriveSeed(cfg.Seed, eng.Name(), 0)
+			job.colorSeed = deriveSeed(cfg.Seed, "colorize", 0)
+			job.phase = t
+		}
+		jobs = append(jobs, job)
+	}
+
+	// Render frames concurrently. Each frame has its own seed and
+	// params, so results do not depend on scheduling; they are stored
+	// by index to keep the output order.
+	workers := anim.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
 
-		// convert to paletted
-		pimg := image.NewPaletted(img.Bounds(), stdpalette.Plan9)
-		draw.FloydSteinberg.Draw(pimg, img.Bounds(), img, image.Point{})
-		images = append(images, pimg)
-		delays = append(delays, int(100/anim.FPS))
+	images := make([]image.Image, frames)
+	err = parallel(frames, workers, func(i int) error {
+		var err error
+		images[i], err = renderFrame(ctx, cfg, eng, looper, rend, jobs[i])
+		return err
+	})
+	if err != nil {
+		return err
 	}
 
 	if anim.LogFrames {
@@ -136,20 +201,148 @@ func Run(cfg *config.Config, eng core.Engine) error {
 		}
 	}
 
-	// save GIF
+	if anim.Format == "frames" {
+		return writeFrames(cfg.Out, anim, images, workers)
+	}
+
 	f, err := os.Create(cfg.Out)
 	if err != nil {
 		return fmt.Errorf("failed to create output: %w", err)
 	}
 	defer f.Close()
 
-	return gif.EncodeAll(f, &gif.GIF{
-		Image: images,
-		Delay: delays,
+	if anim.Format == "apng" {
+		return encodeAPNG(f, images, anim.FPS)
+	}
+
+	// adaptive palettes always keep the configured colors and background
+	seeds := make([][]core.RGBA, frames)
+	for i, job := range jobs {
+		seeds[i] = append(append([]core.RGBA{}, job.colors...), job.bg)
+	}
+	return encodeGIF(f, anim, images, seeds, workers)
+}
+
+// resolveFormat picks the output container from anim.Format or, when
+// empty, from the extension of out: ".png"/".apng" write an APNG, no
+// extension (a directory) writes a PNG sequence, anything else a GIF.
+func resolveFormat(anim *config.AnimationConfig, out string) error {
+	if anim.Format == "" {
+		switch strings.ToLower(filepath.Ext(out)) {
+		case ".png", ".apng":
+			anim.Format = "apng"
+		case "":
+			anim.Format = "frames"
+		default:
+			anim.Format = "gif"
+		}
+	}
+	switch anim.Format {
+	case "gif", "apng", "frames":
+		return nil
+	default:
+		return fmt.Errorf("unknown animation format %q", anim.Format)
+	}
+}
+
+// parallel calls fn(i) for every i in [0,n) on up to workers
+// goroutines and returns the first error by index. Once a call fails,
+// remaining indices are skipped.
+func parallel(n, workers int, fn func(i int) error) error {
+	workers = max(1, min(workers, n))
+	errs := make([]error, n)
+
+	next := make(chan int)
+	var failed atomic.Bool
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				if failed.Load() {
+					continue
+				}
+				if errs[i] = fn(i); errs[i] != nil {
+					failed.Store(true)
+				}
+			}
+		}()
+	}
+	for i := 0; i < n; i++ {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// frameJob holds everything needed to produce one frame independently.
+type frameJob struct {
+	frame  int
+	seed   int64
+	params map[string]float64
+	colors []core.RGBA
+	ramp   palette.Gradient // configured palette ramp, or nil
+	bg     core.RGBA
+	phase  float64 // loop phase in [0,1), loop mode only
+
+	colorSeed int64 // seeds cfg.Colorize
+}
+
+// renderFrame generates and renders a single frame.
+// looper is non-nil in loop mode.
+func renderFrame(ctx context.Context, cfg *config.Config, eng core.Engine, looper core.Looper, rend core.Renderer, job frameJob) (image.Image, error) {
+	rng := rand.New(rand.NewSource(job.seed))
+	if job.ramp != nil {
+		ctx = palette.WithRamp(ctx, job.ramp)
+	}
+
+	// generate
+	var scene core.Scene
+	var err error
+	if looper != nil {
+		scene, err = looper.GenerateLoop(ctx, rng, job.params, job.colors, job.phase)
+	} else {
+		scene, err = eng.Generate(ctx, rng, job.params, job.colors)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("frame %d: engine failed: %w", job.frame, err)
+	}
+
+	if cfg.Colorize != nil {
+		s, err := colorize.New(*cfg.Colorize, job.colors, job.ramp, rand.New(rand.NewSource(job.colorSeed)))
+		if err != nil {
+			return nil, fmt.Errorf("frame %d: %w", job.frame, err)
+		}
+		colorize.Apply(&scene, s)
+	}
+
+	// render
+	img, err := rend.Render(scene, core.RenderConfig{
+		Width:       cfg.Width,
+		Height:      cfg.Height,
+		Background:  job.bg,
+		Margin:      cfg.Render.Margin,
+		Supersample: cfg.Render.Supersample,
+		Filter:      cfg.Render.Filter,
+		Tile:        cfg.Render.Tile,
+		Palette:     job.colors,
 	})
+	if err != nil {
+		return nil, fmt.Errorf("frame %d: render failed: %w", job.frame, err)
+	}
+	return img, nil
 }
 
 func logFrames(out string, logs []FrameLog) error {
+	out = strings.TrimRight(out, "/")
 	ext := filepath.Ext(out)
 	logFile := out[0:len(out)-len(ext)] + ".json"
 
@@ -196,17 +389,3 @@ func deriveSeed(root int64, label string, frame int) int64 {
 	sum := h.Sum(nil)
 	return int64(binary.LittleEndian.Uint64(sum[:8]))
 }
-
-func ease(t float64, mode string) float64 {
-	switch mode {
-	case "cosine":
-		// Smooth in/out
-		return 0.5 - 0.5*math.Cos(t*math.Pi)
-	case "sin":
-		// Oscillating wave
-		return 0.5 + 0.5*math.Sin(2*math.Pi*t)
-	default:
-		// Linear fallback
-		return t
-	}
-}
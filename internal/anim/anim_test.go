@@ -0,0 +1,314 @@
+package anim
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"genart/internal/config"
+	"genart/internal/core"
+	"genart/internal/palette"
+)
+
+// dotsEngine scatters random strokes so every frame depends on its seed.
+type dotsEngine struct{}
+
+func (dotsEngine) Name() string { return "dots" }
+
+func (dotsEngine) Generate(_ context.Context, rng *rand.Rand, params map[string]float64, colors []core.RGBA) (core.Scene, error) {
+	scene := core.Scene{}
+	for i := 0; i < 20; i++ {
+		p := core.Vec2{X: rng.Float64(), Y: rng.Float64()}
+		q := core.Vec2{X: p.X + params["len"], Y: p.Y}
+		scene.AddStroke([]core.Vec2{p, q}, false, 0.02, colors[i%len(colors)], 1)
+	}
+	return scene, nil
+}
+
+func TestRunDeterministicAcrossWorkers(t *testing.T) {
+	dir := t.TempDir()
+	var outputs [][]byte
+
+	for _, workers := range []int{1, 4} {
+		out := filepath.Join(dir, "anim.gif")
+		cfg := &config.Config{
+			Width:      32,
+			Height:     32,
+			Out:        out,
+			Seed:       7,
+			Background: core.RGBA{A: 1},
+			Palette:    config.PaletteConfig{Type: "mono", Base: core.RGBA{R: 0.8, G: 0.2, B: 0.2, A: 1}, N: 4},
+			Params:     map[string]float64{"len": 0.1},
+			Animation: &config.AnimationConfig{
+				Duration: 1,
+				FPS:      6,
+				Vary:     map[string]any{"len": []any{0.1, 0.4}},
+				Workers:  workers,
+			},
+		}
+		if err := Run(cfg, dotsEngine{}); err != nil {
+			t.Fatalf("workers=%d: run failed: %v", workers, err)
+		}
+		b, err := os.ReadFile(out)
+		if err != nil {
+			t.Fatal(err)
+		}
+		outputs = append(outputs, b)
+	}
+
+	if !bytes.Equal(outputs[0], outputs[1]) {
+		t.Fatal("output differs between worker counts")
+	}
+}
+
+// loopEngine is dotsEngine with a loop mode.
+type loopEngine struct{ dotsEngine }
+
+func (e loopEngine) GenerateLoop(ctx context.Context, rng *rand.Rand, params map[string]float64, colors []core.RGBA, _ float64) (core.Scene, error) {
+	return e.Generate(ctx, rng, params, colors)
+}
+
+func TestLoopVary(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "loop.gif")
+	cfg := &config.Config{
+		Width:   16,
+		Height:  16,
+		Out:     out,
+		Palette: config.PaletteConfig{Type: "mono", Base: core.RGBA{R: 0.8, A: 1}, N: 3},
+		Params:  map[string]float64{"len": 0.1},
+		Animation: &config.AnimationConfig{
+			Duration:  1,
+			FPS:       6,
+			Loop:      true,
+			LogFrames: true,
+			Vary:      map[string]any{"len": []any{0.1, 0.4}},
+		},
+	}
+	if err := Run(cfg, loopEngine{}); err == nil {
+		t.Fatal("expected error for a track that does not return to its start")
+	}
+
+	cfg.Animation.Vary = map[string]any{"len": []any{
+		map[string]any{"t": 0.0, "value": 0.1},
+		map[string]any{"t": 0.5, "value": 0.4},
+		map[string]any{"t": 1.0, "value": 0.1},
+	}}
+	if err := Run(cfg, loopEngine{}); err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.ReadFile(strings.TrimSuffix(out, ".gif") + ".json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var logs []FrameLog
+	if err := json.Unmarshal(b, &logs); err != nil {
+		t.Fatal(err)
+	}
+	// tracks follow the loop phase, frame/6, so the last frame is one
+	// step before the start rather than a repeat of it
+	if got := logs[5].Params["len"]; math.Abs(got-0.2) > 1e-9 {
+		t.Errorf("last frame len = %g, want 0.2", got)
+	}
+}
+
+func TestRunRejectsUnusedField(t *testing.T) {
+	cfg := &config.Config{
+		Width:     16,
+		Height:    16,
+		Out:       filepath.Join(t.TempDir(), "anim.gif"),
+		Palette:   config.PaletteConfig{Type: "mono", Base: core.RGBA{R: 0.8, A: 1}, N: 3},
+		Field:     &config.FieldConfig{Expr: "x"},
+		Animation: &config.AnimationConfig{Duration: 1, FPS: 4},
+	}
+	err := Run(cfg, dotsEngine{})
+	if err == nil || !strings.Contains(err.Error(), "custom field") {
+		t.Fatalf("got %v, want an error about the field", err)
+	}
+}
+
+func TestDeltaFramesReconstruct(t *testing.T) {
+	pal := color.Palette{color.Black, color.White, color.RGBA{}}
+	frames := make([]*image.Paletted, 3)
+	for i := range frames {
+		frames[i] = image.NewPaletted(image.Rect(0, 0, 8, 8), pal)
+	}
+	frames[1].SetColorIndex(2, 3, 1)
+	frames[1].SetColorIndex(5, 4, 1)
+	frames[2].SetColorIndex(5, 4, 1)
+
+	delta := deltaFrames(frames)
+	if got, want := delta[1].Bounds(), image.Rect(2, 3, 6, 5); got != want {
+		t.Errorf("frame 1 bounds = %v, want %v", got, want)
+	}
+
+	// replay with DisposalNone: transparent pixels keep the canvas
+	canvas := image.NewPaletted(frames[0].Bounds(), pal)
+	for i, d := range delta {
+		b := d.Bounds()
+		for y := b.Min.Y; y < b.Max.Y; y++ {
+			for x := b.Min.X; x < b.Max.X; x++ {
+				if idx := d.ColorIndexAt(x, y); idx != 2 {
+					canvas.SetColorIndex(x, y, idx)
+				}
+			}
+		}
+		if !bytes.Equal(canvas.Pix, frames[i].Pix) {
+			t.Fatalf("frame %d differs after replay", i)
+		}
+	}
+}
+
+func TestEncodeAPNG(t *testing.T) {
+	frames := make([]image.Image, 3)
+	for i := range frames {
+		img := image.NewRGBA(image.Rect(0, 0, 5, 4))
+		for p := 0; p < len(img.Pix); p += 4 {
+			img.Pix[p], img.Pix[p+1], img.Pix[p+2], img.Pix[p+3] = uint8(p*7+i*40), uint8(p), 90, 255
+		}
+		frames[i] = img
+	}
+
+	var buf bytes.Buffer
+	if err := encodeAPNG(&buf, frames, 30); err != nil {
+		t.Fatal(err)
+	}
+
+	// decoders without APNG support see the first frame
+	img, err := png.Decode(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := img.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			r0, g0, b0, _ := img.At(x, y).RGBA()
+			r1, g1, b1, _ := frames[0].At(x, y).RGBA()
+			if r0 != r1 || g0 != g1 || b0 != b1 {
+				t.Fatalf("pixel (%d,%d) differs", x, y)
+			}
+		}
+	}
+
+	// chunk order and fcTL/fdAT sequence numbers
+	var types []string
+	var seqs []uint32
+	data := buf.Bytes()[8:]
+	for len(data) >= 12 {
+		n := binary.BigEndian.Uint32(data)
+		typ := string(data[4:8])
+		types = append(types, typ)
+		if typ == "fcTL" || typ == "fdAT" {
+			seqs = append(seqs, binary.BigEndian.Uint32(data[8:]))
+		}
+		data = data[12+n:]
+	}
+	want := "IHDR acTL fcTL IDAT fcTL fdAT fcTL fdAT IEND"
+	if got := strings.Join(types, " "); got != want {
+		t.Errorf("chunks = %s, want %s", got, want)
+	}
+	for i, s := range seqs {
+		if s != uint32(i) {
+			t.Errorf("sequence numbers = %v, want 0..%d", seqs, len(seqs)-1)
+			break
+		}
+	}
+}
+
+func TestTimelineKeyframes(t *testing.T) {
+	vary := map[string]any{
+		"amp": []any{
+			map[string]any{"t": 0.0, "value": 0.0},
+			map[string]any{"time": 1.0, "value": 10.0, "easing": "step"},
+			map[string]any{"t": 1.0, "value": 20.0},
+		},
+		"bg": []any{[]any{0.0, 0.0, 0.0}, []any{1.0, 0.5, 0.0, 0.5}},
+	}
+	tracks, err := parseTimeline(vary, 2, "linear", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	amp, bg := tracks[0], tracks[1]
+
+	for _, c := range []struct{ t, want float64 }{
+		{0, 0}, {0.25, 5}, {0.5, 10}, {0.75, 10}, {0.99, 10}, {1, 20},
+	} {
+		if got := amp.at(c.t)[0]; math.Abs(got-c.want) > 1e-9 {
+			t.Errorf("amp at %g = %g, want %g", c.t, got, c.want)
+		}
+	}
+	if got := bg.colors(0.5)[0]; got.G != 0.25 || got.A != 0.75 {
+		t.Errorf("bg at 0.5 = %v", got)
+	}
+}
+
+func TestTimelineErrors(t *testing.T) {
+	for name, vary := range map[string]map[string]any{
+		"short form too long": {"amp": []any{1.0, 2.0, 3.0}},
+		"not a list":          {"amp": 1.0},
+		"color for param":     {"amp": []any{[]any{1.0, 0.0, 0.0}, []any{0.0, 0.0, 1.0}}},
+		"number for color":    {"palette.base": []any{1.0, 2.0}},
+		"unknown easing": {"amp": []any{
+			map[string]any{"t": 0.0, "value": 1.0, "easing": "bounce"},
+			map[string]any{"t": 1.0, "value": 2.0},
+		}},
+		"decreasing time": {"amp": []any{
+			map[string]any{"t": 0.5, "value": 1.0},
+			map[string]any{"t": 0.2, "value": 2.0},
+		}},
+		"missing value": {"amp": []any{
+			map[string]any{"t": 0.0},
+			map[string]any{"t": 1.0, "value": 2.0},
+		}},
+	} {
+		if _, err := parseTimeline(vary, 1, "", ""); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestEasingEndpoints(t *testing.T) {
+	for _, name := range []string{"linear", "cosine", "ease-in", "ease-out", "ease-in-out", "elastic", "step", "cubic-bezier(0.25,0.1,0.25,1)"} {
+		e, err := parseEasing(name)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if e(0) != 0 || math.Abs(e(1)-1) > 1e-9 {
+			t.Errorf("%s: e(0)=%g e(1)=%g", name, e(0), e(1))
+		}
+	}
+}
+
+func TestTimelinePalette(t *testing.T) {
+	p0 := map[string]any{"type": "mono", "base": []any{0.2, 0.4, 0.7}, "n": 4.0}
+	p1 := map[string]any{"type": "analogous", "base": []any{0.7, 0.3, 0.1}, "n": 6.0}
+	tracks, err := parseTimeline(map[string]any{"palette": []any{p0, p1}}, 1, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	end := tracks[0].colors(1)
+	want := palette.Analogous(core.RGBA{R: 0.7, G: 0.3, B: 0.1, A: 1}, 6)
+	if len(end) != len(want) {
+		t.Fatalf("got %d colors, want %d", len(end), len(want))
+	}
+	for i := range want {
+		if math.Abs(end[i].R-want[i].R) > 1e-6 || math.Abs(end[i].B-want[i].B) > 1e-6 {
+			t.Errorf("color %d = %v, want %v", i, end[i], want[i])
+		}
+	}
+
+	_, err = parseTimeline(map[string]any{"palette": []any{p0, p1}, "palette.n": []any{2.0, 4.0}}, 1, "", "")
+	if err == nil {
+		t.Error("expected error combining palette and palette.n")
+	}
+}
@@ -0,0 +1,232 @@
+package anim
+
+import (
+	"bufio"
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"image"
+	"image/draw"
+	"io"
+)
+
+var pngSignature = []byte("\x89PNG\r\n\x1a\n")
+
+// PNG color types used by the encoder.
+const (
+	pngTruecolor      = 2
+	pngTruecolorAlpha = 6
+)
+
+// encodeAPNG writes frames as an animated PNG that loops forever.
+// Every frame is stored whole in 8-bit RGB, or RGBA if any frame has
+// transparency, and shown for 1/fps seconds. Viewers without APNG
+// support display the first frame.
+func encodeAPNG(w io.Writer, frames []image.Image, fps int) error {
+	if len(frames) == 0 {
+		return fmt.Errorf("apng: no frames")
+	}
+	if fps <= 0 || fps > 0xffff {
+		return fmt.Errorf("apng: invalid fps %d", fps)
+	}
+	b := frames[0].Bounds()
+	for i, f := range frames {
+		if f.Bounds().Size() != b.Size() {
+			return fmt.Errorf("apng: frame %d is %v, want %v", i, f.Bounds().Size(), b.Size())
+		}
+	}
+
+	colorType := byte(pngTruecolor)
+	for _, f := range frames {
+		if o, ok := f.(interface{ Opaque() bool }); !ok || !o.Opaque() {
+			colorType = pngTruecolorAlpha
+			break
+		}
+	}
+
+	bw := bufio.NewWriter(w)
+	cw := &chunkWriter{w: bw}
+	if _, err := bw.Write(pngSignature); err != nil {
+		return err
+	}
+
+	ihdr := make([]byte, 13)
+	binary.BigEndian.PutUint32(ihdr[0:], uint32(b.Dx()))
+	binary.BigEndian.PutUint32(ihdr[4:], uint32(b.Dy()))
+	ihdr[8] = 8 // bit depth
+	ihdr[9] = colorType
+	cw.write("IHDR", ihdr)
+
+	actl := make([]byte, 8)
+	binary.BigEndian.PutUint32(actl[0:], uint32(len(frames)))
+	binary.BigEndian.PutUint32(actl[4:], 0) // loop forever
+	cw.write("acTL", actl)
+
+	// fcTL and fdAT chunks share one sequence counter
+	seq := uint32(0)
+	for i, f := range frames {
+		fctl := make([]byte, 26)
+		binary.BigEndian.PutUint32(fctl[0:], seq)
+		binary.BigEndian.PutUint32(fctl[4:], uint32(b.Dx()))
+		binary.BigEndian.PutUint32(fctl[8:], uint32(b.Dy()))
+		// x and y offsets stay zero: every frame covers the canvas
+		binary.BigEndian.PutUint16(fctl[20:], 1)
+		binary.BigEndian.PutUint16(fctl[22:], uint16(fps))
+		// dispose op 0 (none), blend op 0 (source)
+		cw.write("fcTL", fctl)
+		seq++
+
+		data, err := compressFrame(f, colorType)
+		if err != nil {
+			return fmt.Errorf("apng: frame %d: %w", i, err)
+		}
+		if i == 0 {
+			cw.write("IDAT", data)
+			continue
+		}
+		fdat := make([]byte, 4, 4+len(data))
+		binary.BigEndian.PutUint32(fdat, seq)
+		cw.write("fdAT", append(fdat, data...))
+		seq++
+	}
+
+	cw.write("IEND", nil)
+	if cw.err != nil {
+		return cw.err
+	}
+	return bw.Flush()
+}
+
+// chunkWriter writes PNG chunks and keeps the first error.
+type chunkWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (c *chunkWriter) write(typ string, data []byte) {
+	if c.err != nil {
+		return
+	}
+	head := make([]byte, 8)
+	binary.BigEndian.PutUint32(head, uint32(len(data)))
+	copy(head[4:], typ)
+
+	crc := crc32.NewIEEE()
+	crc.Write(head[4:])
+	crc.Write(data)
+	tail := binary.BigEndian.AppendUint32(nil, crc.Sum32())
+
+	for _, p := range [][]byte{head, data, tail} {
+		if _, err := c.w.Write(p); err != nil {
+			c.err = err
+			return
+		}
+	}
+}
+
+// compressFrame returns the zlib stream of img's filtered scanlines.
+func compressFrame(img image.Image, colorType byte) ([]byte, error) {
+	b := img.Bounds()
+	nrgba := image.NewNRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(nrgba, nrgba.Bounds(), img, b.Min, draw.Src)
+
+	bpp := 4
+	if colorType == pngTruecolor {
+		bpp = 3
+	}
+	rowLen := b.Dx() * bpp
+
+	var buf bytes.Buffer
+	zw, err := zlib.NewWriterLevel(&buf, zlib.BestSpeed)
+	if err != nil {
+		return nil, err
+	}
+
+	prev := make([]byte, rowLen)
+	cur := make([]byte, rowLen)
+	for y := 0; y < b.Dy(); y++ {
+		row := nrgba.Pix[y*nrgba.Stride : y*nrgba.Stride+b.Dx()*4]
+		if bpp == 4 {
+			copy(cur, row)
+		} else {
+			for x := 0; x < b.Dx(); x++ {
+				copy(cur[x*3:x*3+3], row[x*4:x*4+3])
+			}
+		}
+		if _, err := zw.Write(filterRow(cur, prev, bpp)); err != nil {
+			return nil, err
+		}
+		prev, cur = cur, prev
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// filterRow tries every PNG filter on cur and returns the filter byte
+// followed by the candidate with the smallest sum of absolute values,
+// the same heuristic image/png uses.
+func filterRow(cur, prev []byte, bpp int) []byte {
+	best := []byte(nil)
+	bestSum := -1
+	out := make([]byte, 1+len(cur))
+
+	for ft := byte(0); ft <= 4; ft++ {
+		out[0] = ft
+		sum := 0
+		for i, x := range cur {
+			var a, c byte
+			if i >= bpp {
+				a, c = cur[i-bpp], prev[i-bpp]
+			}
+			up := prev[i]
+			var v byte
+			switch ft {
+			case 0:
+				v = x
+			case 1:
+				v = x - a
+			case 2:
+				v = x - up
+			case 3:
+				v = x - byte((int(a)+int(up))/2)
+			case 4:
+				v = x - paeth(a, up, c)
+			}
+			out[1+i] = v
+			if d := int(int8(v)); d < 0 {
+				sum -= d
+			} else {
+				sum += d
+			}
+		}
+		if bestSum < 0 || sum < bestSum {
+			bestSum = sum
+			best = append(best[:0], out...)
+		}
+	}
+	return best
+}
+
+// paeth is the PNG Paeth predictor.
+func paeth(a, b, c byte) byte {
+	p := int(a) + int(b) - int(c)
+	pa, pb, pc := abs(p-int(a)), abs(p-int(b)), abs(p-int(c))
+	if pa <= pb && pa <= pc {
+		return a
+	}
+	if pb <= pc {
+		return b
+	}
+	return c
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
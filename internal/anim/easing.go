@@ -0,0 +1,107 @@
+package anim
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// easeFunc maps linear progress through a segment, in [0,1], to
+// interpolation weight. Most easings return 0 at 0 and 1 at 1.
+type easeFunc func(t float64) float64
+
+// parseEasing resolves an easing name:
+//
+//	linear, cosine, sin, ease-in, ease-out, ease-in-out, elastic, step,
+//	ping-pong, cubic-bezier(x1,y1,x2,y2)
+//
+// An empty name is linear.
+func parseEasing(name string) (easeFunc, error) {
+	switch name {
+	case "", "linear":
+		return func(t float64) float64 { return t }, nil
+	case "cosine":
+		// Smooth in/out
+		return func(t float64) float64 { return 0.5 - 0.5*math.Cos(t*math.Pi) }, nil
+	case "sin":
+		// Oscillating wave
+		return func(t float64) float64 { return 0.5 + 0.5*math.Sin(2*math.Pi*t) }, nil
+	case "ease-in":
+		return func(t float64) float64 { return t * t * t }, nil
+	case "ease-out":
+		return func(t float64) float64 { return 1 - math.Pow(1-t, 3) }, nil
+	case "ease-in-out":
+		return func(t float64) float64 {
+			if t < 0.5 {
+				return 4 * t * t * t
+			}
+			return 1 - math.Pow(-2*t+2, 3)/2
+		}, nil
+	case "elastic":
+		// overshoots and settles, like a spring
+		return func(t float64) float64 {
+			if t <= 0 || t >= 1 {
+				return t
+			}
+			return math.Pow(2, -10*t)*math.Sin((10*t-0.75)*2*math.Pi/3) + 1
+		}, nil
+	case "step":
+		// hold the start value for the whole segment
+		return func(t float64) float64 {
+			if t >= 1 {
+				return 1
+			}
+			return 0
+		}, nil
+	case "ping-pong":
+		// out to the end value and back within the segment
+		return func(t float64) float64 { return 1 - math.Abs(2*t-1) }, nil
+	}
+
+	if args, ok := strings.CutPrefix(name, "cubic-bezier("); ok && strings.HasSuffix(args, ")") {
+		parts := strings.Split(strings.TrimSuffix(args, ")"), ",")
+		if len(parts) != 4 {
+			return nil, fmt.Errorf("easing %q: cubic-bezier needs 4 values", name)
+		}
+		var p [4]float64
+		for i, s := range parts {
+			v, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+			if err != nil {
+				return nil, fmt.Errorf("easing %q: %w", name, err)
+			}
+			p[i] = v
+		}
+		if p[0] < 0 || p[0] > 1 || p[2] < 0 || p[2] > 1 {
+			return nil, fmt.Errorf("easing %q: x1 and x2 must be in [0,1]", name)
+		}
+		return cubicBezier(p[0], p[1], p[2], p[3]), nil
+	}
+
+	return nil, fmt.Errorf("unknown easing %q", name)
+}
+
+// cubicBezier returns the CSS-style timing curve through (0,0),
+// (x1,y1), (x2,y2), (1,1). x is monotonic for x1, x2 in [0,1], so the
+// curve parameter for a given t is found by bisection.
+func cubicBezier(x1, y1, x2, y2 float64) easeFunc {
+	bez := func(s, p1, p2 float64) float64 {
+		u := 1 - s
+		return 3*u*u*s*p1 + 3*u*s*s*p2 + s*s*s
+	}
+	return func(t float64) float64 {
+		if t <= 0 || t >= 1 {
+			return t
+		}
+		lo, hi := 0.0, 1.0
+		for i := 0; i < 40; i++ {
+			mid := (lo + hi) / 2
+			if bez(mid, x1, x2) < t {
+				lo = mid
+			} else {
+				hi = mid
+			}
+		}
+		return bez((lo+hi)/2, y1, y2)
+	}
+}
@@ -0,0 +1,73 @@
+package anim
+
+import (
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+
+	"genart/internal/config"
+)
+
+// Manifest describes a directory of PNG frames.
+type Manifest struct {
+	Width    int      `json:"width"`
+	Height   int      `json:"height"`
+	FPS      int      `json:"fps"`
+	Duration float64  `json:"duration"` // seconds
+	Pattern  string   `json:"pattern"`  // printf-style frame name, e.g. for ffmpeg -i
+	Files    []string `json:"files"`
+}
+
+// writeFrames saves every frame as a zero-padded PNG in dir, followed
+// by manifest.json listing them in order.
+func writeFrames(dir string, anim *config.AnimationConfig, frames []image.Image, workers int) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create frames directory: %w", err)
+	}
+
+	digits := max(4, len(fmt.Sprint(len(frames)-1)))
+	pattern := fmt.Sprintf("frame_%%0%dd.png", digits)
+
+	files := make([]string, len(frames))
+	for i := range files {
+		files[i] = fmt.Sprintf(pattern, i)
+	}
+
+	err := parallel(len(frames), workers, func(i int) error {
+		f, err := os.Create(filepath.Join(dir, files[i]))
+		if err != nil {
+			return fmt.Errorf("frame %d: %w", i, err)
+		}
+		if err := png.Encode(f, frames[i]); err != nil {
+			f.Close()
+			return fmt.Errorf("frame %d: %w", i, err)
+		}
+		return f.Close()
+	})
+	if err != nil {
+		return err
+	}
+
+	b := frames[0].Bounds()
+	m := Manifest{
+		Width:    b.Dx(),
+		Height:   b.Dy(),
+		FPS:      anim.FPS,
+		Duration: float64(len(frames)) / float64(anim.FPS),
+		Pattern:  pattern,
+		Files:    files,
+	}
+
+	f, err := os.Create(filepath.Join(dir, "manifest.json"))
+	if err != nil {
+		return fmt.Errorf("failed to create manifest: %w", err)
+	}
+	defer f.Close()
+
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+	return enc.Encode(m)
+}
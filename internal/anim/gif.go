@@ -0,0 +1,181 @@
+package anim
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	stdpalette "image/color/palette"
+	"image/gif"
+	"io"
+
+	"genart/internal/config"
+	"genart/internal/core"
+	"genart/internal/quantize"
+)
+
+// resolveGIF fills in the GIF encoding defaults and validates them
+// before any frame is rendered.
+func resolveGIF(anim *config.AnimationConfig) error {
+	switch anim.Quantize {
+	case "":
+		anim.Quantize = "median-cut"
+	case "plan9", "median-cut":
+	default:
+		return fmt.Errorf("unknown quantizer %q", anim.Quantize)
+	}
+	switch anim.PaletteScope {
+	case "":
+		anim.PaletteScope = "global"
+	case "global", "frame":
+	default:
+		return fmt.Errorf("unknown palette scope %q", anim.PaletteScope)
+	}
+	switch anim.Dither {
+	case "":
+		anim.Dither = quantize.DitherFloydSteinberg
+	case quantize.DitherNone, quantize.DitherFloydSteinberg, quantize.DitherBayer:
+	default:
+		return fmt.Errorf("unknown dither mode %q", anim.Dither)
+	}
+	return nil
+}
+
+// encodeGIF quantizes the rendered frames and writes an animated GIF.
+// anim must already be resolved.
+// seeds[i] lists the colors frame i was drawn with (palette and
+// background); adaptive palettes always contain them.
+func encodeGIF(w io.Writer, anim *config.AnimationConfig, frames []image.Image, seeds [][]core.RGBA, workers int) error {
+	// one palette slot is reserved for transparency in delta mode
+	size := 256
+	if anim.Delta {
+		size = 255
+	}
+
+	// withTransparent appends the entry delta frames use for unchanged pixels
+	withTransparent := func(p color.Palette) color.Palette {
+		if !anim.Delta {
+			return p
+		}
+		return append(append(make(color.Palette, 0, len(p)+1), p...), color.RGBA{})
+	}
+
+	// Frames sharing a palette share one slice so the encoder writes a
+	// single global color table.
+	palettes := make([]color.Palette, len(frames))
+	switch {
+	case anim.Quantize == "plan9":
+		pal := stdpalette.Plan9
+		if anim.Delta {
+			// drop a pale yellow rather than pure white
+			pal = append(append(color.Palette{}, pal[:254]...), pal[255])
+		}
+		pal = withTransparent(pal)
+		for i := range palettes {
+			palettes[i] = pal
+		}
+	case anim.PaletteScope == "frame":
+		err := parallel(len(frames), workers, func(i int) error {
+			palettes[i] = withTransparent(quantize.MedianCut(frames[i:i+1], size, seeds[i]))
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	default:
+		all := make([]core.RGBA, 0)
+		for _, s := range seeds {
+			all = append(all, s...)
+		}
+		pal := withTransparent(quantize.MedianCut(frames, size, all))
+		for i := range palettes {
+			palettes[i] = pal
+		}
+	}
+
+	// quantize every frame against its palette
+	images := make([]*image.Paletted, len(frames))
+	err := parallel(len(frames), workers, func(i int) error {
+		pal := palettes[i]
+		if anim.Delta {
+			pal = pal[:len(pal)-1] // never dither into the transparent entry
+		}
+		pimg := image.NewPaletted(frames[i].Bounds(), pal)
+		if err := quantize.Draw(pimg, frames[i], anim.Dither); err != nil {
+			return err
+		}
+		pimg.Palette = palettes[i]
+		images[i] = pimg
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	delays := make([]int, len(images))
+	for i := range delays {
+		delays[i] = int(100 / anim.FPS)
+	}
+	out := &gif.GIF{
+		Image: images,
+		Delay: delays,
+	}
+	if anim.Delta {
+		out.Image = deltaFrames(images)
+		out.Disposal = make([]byte, len(images))
+		for i := range out.Disposal {
+			out.Disposal[i] = gif.DisposalNone
+		}
+	}
+	if anim.Quantize != "plan9" && anim.PaletteScope == "global" {
+		b := images[0].Bounds()
+		out.Config = image.Config{ColorModel: palettes[0], Width: b.Dx(), Height: b.Dy()}
+	}
+
+	return gif.EncodeAll(w, out)
+}
+
+// deltaFrames keeps the first frame whole and reduces every later
+// frame to the rectangle of pixels that changed, with unchanged pixels
+// set to the transparent (last) palette index. Combined with
+// DisposalNone the decoder reproduces the original frames exactly.
+func deltaFrames(images []*image.Paletted) []*image.Paletted {
+	out := make([]*image.Paletted, len(images))
+	out[0] = images[0]
+
+	for i := 1; i < len(images); i++ {
+		prev, cur := images[i-1], images[i]
+		b := cur.Bounds()
+		transparent := uint8(len(cur.Palette) - 1)
+
+		// bounding box of changed pixels
+		changed := image.Rectangle{}
+		for y := b.Min.Y; y < b.Max.Y; y++ {
+			for x := b.Min.X; x < b.Max.X; x++ {
+				if !sameColor(prev, cur, x, y) {
+					changed = changed.Union(image.Rect(x, y, x+1, y+1))
+				}
+			}
+		}
+		if changed.Empty() {
+			changed = image.Rect(b.Min.X, b.Min.Y, b.Min.X+1, b.Min.Y+1)
+		}
+
+		d := image.NewPaletted(changed, cur.Palette)
+		for y := changed.Min.Y; y < changed.Max.Y; y++ {
+			for x := changed.Min.X; x < changed.Max.X; x++ {
+				if sameColor(prev, cur, x, y) {
+					d.SetColorIndex(x, y, transparent)
+				} else {
+					d.SetColorIndex(x, y, cur.ColorIndexAt(x, y))
+				}
+			}
+		}
+		out[i] = d
+	}
+	return out
+}
+
+// sameColor compares pixels by color, since frames may use different palettes.
+func sameColor(a, b *image.Paletted, x, y int) bool {
+	return a.Palette[a.ColorIndexAt(x, y)] == b.Palette[b.ColorIndexAt(x, y)]
+}
@@ -0,0 +1,336 @@
+package anim
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+
+	"genart/internal/config"
+	"genart/internal/core"
+	"genart/internal/palette"
+)
+
+// Animatable properties besides engine params.
+const (
+	keyPalette     = "palette"
+	keyPaletteBase = "palette.base"
+	keyPaletteN    = "palette.n"
+	keyBackground  = "bg"
+)
+
+// keyframe is one value on a track. ease shapes the segment from this
+// keyframe to the next.
+type keyframe struct {
+	t     float64 // fraction of the animation in [0,1]
+	value []float64
+	ease  easeFunc
+}
+
+// track animates one property through its keyframes, sorted by t.
+// Color tracks hold their colors flattened as coordinates in space
+// plus alpha, so plain interpolation blends in that space.
+type track struct {
+	key   string
+	keys  []keyframe
+	space core.ColorSpace
+}
+
+// parseTimeline turns animation.vary into tracks. Each entry is either
+// the short form [start, end], spanning the whole animation, or a list
+// of keyframe objects:
+//
+//	{"t": 0.5, "value": 2, "easing": "ease-in"}
+//
+// where "t" is a fraction of the animation (or "time" is in seconds)
+// and "easing" shapes the segment towards the next keyframe. Values are
+// numbers, [r,g,b] / [r,g,b,a] for palette.base and bg, or whole
+// palette objects like the config's for palette. defEase applies to
+// segments without their own easing. Colors blend in space; when empty,
+// palette.base and bg blend in sRGB and whole palettes in OKLab.
+func parseTimeline(vary map[string]any, duration float64, defEase string, space core.ColorSpace) ([]track, error) {
+	def, err := parseEasing(defEase)
+	if err != nil {
+		return nil, err
+	}
+
+	// sorted for stable error messages
+	names := make([]string, 0, len(vary))
+	for k := range vary {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	tracks := make([]track, 0, len(vary))
+	for _, key := range names {
+		tr, err := parseTrack(key, vary[key], duration, def)
+		if err != nil {
+			return nil, fmt.Errorf("vary %q: %w", key, err)
+		}
+		switch key {
+		case keyPalette:
+			tr.space = cmp.Or(space, core.SpaceOKLab)
+			encodeColors(tr.keys, tr.space)
+		case keyPaletteBase, keyBackground:
+			tr.space = cmp.Or(space, core.SpaceSRGB)
+			encodeColors(tr.keys, tr.space)
+		}
+		tracks = append(tracks, tr)
+	}
+
+	if _, ok := vary[keyPalette]; ok {
+		for _, k := range []string{keyPaletteBase, keyPaletteN} {
+			if _, ok := vary[k]; ok {
+				return nil, fmt.Errorf("vary: %q and %q cannot be combined", keyPalette, k)
+			}
+		}
+	}
+	return tracks, nil
+}
+
+func parseTrack(key string, raw any, duration float64, def easeFunc) (track, error) {
+	arr, ok := raw.([]any)
+	if !ok || len(arr) < 2 {
+		return track{}, fmt.Errorf("want [start, end] or a list of at least 2 keyframes")
+	}
+
+	tr := track{key: key}
+	if !isKeyframe(arr[0]) {
+		// short form
+		if len(arr) != 2 {
+			return track{}, fmt.Errorf("short form takes exactly [start, end], use keyframe objects for more")
+		}
+		for i, v := range arr {
+			value, err := parseValue(key, v)
+			if err != nil {
+				return track{}, err
+			}
+			tr.keys = append(tr.keys, keyframe{t: float64(i), value: value, ease: def})
+		}
+		return tr, nil
+	}
+
+	for i, v := range arr {
+		obj, ok := v.(map[string]any)
+		if !ok {
+			return track{}, fmt.Errorf("keyframe %d: want an object", i)
+		}
+		k, err := parseKeyframe(key, obj, duration, def)
+		if err != nil {
+			return track{}, fmt.Errorf("keyframe %d: %w", i, err)
+		}
+		if i > 0 && k.t <= tr.keys[i-1].t {
+			return track{}, fmt.Errorf("keyframe %d: times must increase", i)
+		}
+		if i > 0 && key != keyPalette && len(k.value) != len(tr.keys[0].value) {
+			return track{}, fmt.Errorf("keyframe %d: value has %d components, want %d", i, len(k.value), len(tr.keys[0].value))
+		}
+		tr.keys = append(tr.keys, k)
+	}
+	return tr, nil
+}
+
+// isKeyframe tells keyframe objects from short-form values, which may
+// be objects themselves (palettes).
+func isKeyframe(v any) bool {
+	obj, ok := v.(map[string]any)
+	if !ok {
+		return false
+	}
+	_, hasT := obj["t"]
+	_, hasTime := obj["time"]
+	return hasT || hasTime
+}
+
+func parseKeyframe(key string, obj map[string]any, duration float64, def easeFunc) (keyframe, error) {
+	k := keyframe{ease: def}
+	hasT := false
+	for name, v := range obj {
+		switch name {
+		case "t", "time":
+			f, ok := v.(float64)
+			if !ok {
+				return keyframe{}, fmt.Errorf("%q must be a number", name)
+			}
+			if hasT {
+				return keyframe{}, fmt.Errorf("set only one of \"t\" and \"time\"")
+			}
+			hasT = true
+			k.t = f
+			if name == "time" {
+				k.t = f / duration
+			}
+		case "value":
+			value, err := parseValue(key, v)
+			if err != nil {
+				return keyframe{}, err
+			}
+			k.value = value
+		case "easing":
+			s, ok := v.(string)
+			if !ok {
+				return keyframe{}, fmt.Errorf("easing must be a string")
+			}
+			e, err := parseEasing(s)
+			if err != nil {
+				return keyframe{}, err
+			}
+			k.ease = e
+		default:
+			return keyframe{}, fmt.Errorf("unknown field %q", name)
+		}
+	}
+	if !hasT {
+		return keyframe{}, fmt.Errorf("missing \"t\" or \"time\"")
+	}
+	if k.t < 0 || k.t > 1 {
+		return keyframe{}, fmt.Errorf("time %g is outside the animation", k.t)
+	}
+	if k.value == nil {
+		return keyframe{}, fmt.Errorf("missing value")
+	}
+	return k, nil
+}
+
+// parseValue checks v against the shape key expects. Palettes are
+// resolved to their colors, flattened as r,g,b,a.
+func parseValue(key string, v any) ([]float64, error) {
+	switch key {
+	case keyPalette:
+		colors, err := parsePalette(v)
+		if err != nil {
+			return nil, err
+		}
+		out := make([]float64, 0, 4*len(colors))
+		for _, c := range colors {
+			out = append(out, c.R, c.G, c.B, c.A)
+		}
+		return out, nil
+	case keyPaletteBase, keyBackground:
+		if hex, ok := v.(string); ok {
+			c, err := core.ParseHex(hex)
+			if err != nil {
+				return nil, err
+			}
+			return []float64{c.R, c.G, c.B, c.A}, nil
+		}
+		c, ok := toFloatSlice(v)
+		if !ok || len(c) < 3 || len(c) > 4 {
+			return nil, fmt.Errorf("value %v: want [r,g,b], [r,g,b,a] or \"#rrggbb\"", v)
+		}
+		if len(c) == 3 {
+			c = append(c, 1)
+		}
+		return c, nil
+	default:
+		f, ok := v.(float64)
+		if !ok {
+			return nil, fmt.Errorf("value %v: want a number", v)
+		}
+		if key == keyPaletteN && f < 1 {
+			return nil, fmt.Errorf("value %v: palette needs at least 1 color", f)
+		}
+		return []float64{f}, nil
+	}
+}
+
+func parsePalette(v any) ([]core.RGBA, error) {
+	if _, ok := v.(map[string]any); !ok {
+		return nil, fmt.Errorf("value %v: want a palette object", v)
+	}
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.DisallowUnknownFields()
+	var pc config.PaletteConfig
+	if err := dec.Decode(&pc); err != nil {
+		return nil, fmt.Errorf("palette: %w", err)
+	}
+	colors, err := palette.Resolve(&pc)
+	if err != nil {
+		return nil, fmt.Errorf("palette: %w", err)
+	}
+	return colors, nil
+}
+
+// encodeColors resamples the color lists of keys to a common size and
+// converts them to coordinates in space. OKLCH hues are unwrapped
+// against the previous keyframe so each segment turns the short way.
+func encodeColors(keys []keyframe, space core.ColorSpace) {
+	n := 0
+	for _, k := range keys {
+		n = max(n, len(k.value)/4)
+	}
+	for i, k := range keys {
+		colors := make([]core.RGBA, len(k.value)/4)
+		for j := range colors {
+			v := k.value[4*j:]
+			colors[j] = core.RGBA{R: v[0], G: v[1], B: v[2], A: v[3]}
+		}
+		out := make([]float64, 0, 4*n)
+		for j, c := range palette.Resample(colors, n) {
+			v := c.In(space)
+			if space == core.SpaceOKLCH && i > 0 {
+				v[2] = core.NearestHue(keys[i-1].value[4*j+2], v[2])
+			}
+			out = append(out, v[0], v[1], v[2], c.A)
+		}
+		keys[i].value = out
+	}
+}
+
+// colors evaluates a color track at t.
+func (tr track) colors(t float64) []core.RGBA {
+	v := tr.at(t)
+	colors := make([]core.RGBA, len(v)/4)
+	for i := range colors {
+		colors[i] = core.FromSpace(tr.space, [3]float64{v[4*i], v[4*i+1], v[4*i+2]}, v[4*i+3])
+	}
+	return colors
+}
+
+// at returns the track value at animation fraction t. Before the first
+// keyframe and after the last, the nearest value holds. Within a
+// segment the easing applies even at its ends, since some easings
+// (sin, ping-pong) do not start at 0 or end at 1.
+func (tr track) at(t float64) []float64 {
+	ks := tr.keys
+	if t < ks[0].t {
+		return ks[0].value
+	}
+	for i := 1; i < len(ks); i++ {
+		if t <= ks[i].t {
+			a, b := ks[i-1], ks[i]
+			w := a.ease((t - a.t) / (b.t - a.t))
+			out := make([]float64, len(a.value))
+			for j := range out {
+				out[j] = lerp(a.value[j], b.value[j], w)
+			}
+			return out
+		}
+	}
+	return ks[len(ks)-1].value
+}
+
+// checkLoop makes sure every track ends where it starts, so a looping
+// animation flows from its last frame back into the first.
+func checkLoop(tracks []track) error {
+	for _, tr := range tracks {
+		start, end := tr.at(0), tr.at(1)
+		for i := range start {
+			if math.Abs(start[i]-end[i]) > 1e-9 {
+				return fmt.Errorf("vary %q: a looping animation needs the value at the end to match the start", tr.key)
+			}
+		}
+	}
+	return nil
+}
+
+// paletteN rounds an interpolated palette size.
+func paletteN(v float64) int {
+	return max(1, int(math.Round(v)))
+}
@@ -0,0 +1,66 @@
+package colorize
+
+import (
+	"math"
+
+	"genart/internal/core"
+	"genart/internal/noise"
+	"genart/internal/palette"
+)
+
+// Coloring modes engines can offer through a "colorMode" param.
+const (
+	ModePalette = "palette" // snap to palette entries
+	ModeRamp    = "ramp"    // blend continuously between entries
+)
+
+// Modes and RampSpaces list enum options, in param index order.
+var (
+	Modes      = []string{ModePalette, ModeRamp}
+	RampSpaces = []string{"oklab", "oklch", "lab", "linear", "srgb"}
+)
+
+// NoiseParams are the params an engine adds to its spec to let configs
+// choose ramp coloring; see NoiseColorerFor.
+var NoiseParams = []core.Param{
+	core.EnumParam("colorMode", ModePalette, Modes, "palette: snap to palette colors; ramp: blend smoothly between them"),
+	core.EnumParam("rampSpace", RampSpaces[0], RampSpaces, "interpolation space for ramp coloring, unless the palette configures a ramp"),
+}
+
+// Blend returns the color at t in [0,1] along colors, interpolating
+// between neighbouring entries in space instead of snapping to one.
+func Blend(colors []core.RGBA, t float64, space core.ColorSpace) core.RGBA {
+	return palette.NewRamp(colors, space).At(math.Max(0, math.Min(1, t)))
+}
+
+// RampFromNoise samples g continuously by the noise value at (x, y).
+func RampFromNoise(g palette.Gradient, noiseField noise.ScalarField2D, x, y, factor float64) core.RGBA {
+	nval := noiseField.At(x*factor, y*factor) // -1..1
+	return g.At((nval + 1) / 2)
+}
+
+// NoiseColorer colors points by noise, either snapping to palette
+// entries like PickColorFromNoise or, in ModeRamp, sampling ramp
+// continuously. A nil ramp blends through the palette in space.
+func NoiseColorer(colors []core.RGBA, ramp palette.Gradient, noiseField noise.ScalarField2D, factor float64, mode string, space core.ColorSpace) func(x, y float64) core.RGBA {
+	if mode == ModeRamp && ramp == nil && len(colors) > 0 {
+		ramp = palette.NewRamp(colors, space)
+	}
+	if mode == ModeRamp && ramp != nil {
+		return func(x, y float64) core.RGBA {
+			return RampFromNoise(ramp, noiseField, x, y, factor)
+		}
+	}
+	return func(x, y float64) core.RGBA {
+		return PickColorFromNoise(colors, noiseField, x, y, factor)
+	}
+}
+
+// NoiseColorerFor is NoiseColorer configured by params that include
+// NoiseParams with defaults filled in. Engines pass the ramp carried by
+// their context; see palette.RampFrom.
+func NoiseColorerFor(colors []core.RGBA, ramp palette.Gradient, noiseField noise.ScalarField2D, factor float64, params map[string]float64) func(x, y float64) core.RGBA {
+	mode := Modes[int(params["colorMode"])]
+	space := core.ColorSpace(RampSpaces[int(params["rampSpace"])])
+	return NoiseColorer(colors, ramp, noiseField, factor, mode, space)
+}
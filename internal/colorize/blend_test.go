@@ -0,0 +1,142 @@
+package colorize
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"genart/internal/config"
+	"genart/internal/core"
+	"genart/internal/palette"
+)
+
+func TestBlend(t *testing.T) {
+	colors := []core.RGBA{{R: 1, A: 1}, {G: 1, A: 1}, {B: 1, A: 1}}
+
+	if got := Blend(colors, 0, core.SpaceOKLab); got != colors[0] {
+		t.Errorf("t=0: got %v", got)
+	}
+	if got := Blend(colors, 1, core.SpaceOKLab); got != colors[2] {
+		t.Errorf("t=1: got %v", got)
+	}
+	if got := Blend(colors, 0.5, core.SpaceOKLCH); got != colors[1] {
+		t.Errorf("t=0.5: got %v", got)
+	}
+	if got := Blend(colors, 0.25, core.SpaceSRGB); got.R != 0.5 || got.G != 0.5 {
+		t.Errorf("t=0.25 in srgb: got %v", got)
+	}
+}
+
+func line(x0, y0, x1, y1 float64) core.Stroke {
+	return core.Stroke{Path: core.Path{Points: []core.Vec2{{X: x0, Y: y0}, {X: x1, Y: y1}}}, Alpha: 0.5}
+}
+
+func TestStrategies(t *testing.T) {
+	colors := []core.RGBA{{R: 1, A: 1}, {G: 1, A: 1}}
+	rng := rand.New(rand.NewSource(1))
+
+	tests := []struct {
+		strategy string
+		a, b     core.Stroke // a should get colors[0], b colors[1]
+	}{
+		{ByGradient, line(0, 0, 0, 0.2), line(1, 0, 1, 0.2)},
+		{ByAngle, line(0.5, 0.5, 0.5, 0), line(0.5, 0.5, 1, 0.5)},
+		{ByRadial, line(0.45, 0.5, 0.55, 0.5), line(0, 0, 0.1, 0)},
+		{ByIndex, line(0, 0, 1, 1), line(0, 0, 1, 1)},
+		{ByLength, line(0, 0, 0.1, 0), line(0, 0, 1, 0)},
+	}
+	for _, tt := range tests {
+		s, err := New(config.ColorizeConfig{Strategy: tt.strategy}, colors, nil, rng)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.strategy, err)
+		}
+		scene := core.Scene{Items: []core.Item{tt.a, tt.b}}
+		Apply(&scene, s)
+		a, b := scene.Items[0].(core.Stroke), scene.Items[1].(core.Stroke)
+		if a.Color != colors[0] || b.Color != colors[1] {
+			t.Errorf("%s: got %v, %v", tt.strategy, a.Color, b.Color)
+		}
+		if a.Alpha != 0.5 {
+			t.Errorf("%s: alpha changed to %g", tt.strategy, a.Alpha)
+		}
+	}
+}
+
+func TestSteps(t *testing.T) {
+	s := steps{{R: 1, A: 1}, {G: 1, A: 1}, {B: 1, A: 1}}
+	for _, c := range []struct {
+		t    float64
+		want int
+	}{{0, 0}, {0.3, 0}, {0.34, 1}, {0.66, 1}, {0.67, 2}, {1, 2}} {
+		if got := s.At(c.t); got != s[c.want] {
+			t.Errorf("t=%g: got %v, want entry %d", c.t, got, c.want)
+		}
+	}
+}
+
+func TestStrategyRamp(t *testing.T) {
+	colors := []core.RGBA{{A: 1}, {R: 1, G: 1, B: 1, A: 1}}
+	s, err := New(config.ColorizeConfig{Strategy: ByGradient, Mode: ModeRamp, Space: "srgb"}, colors, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := s.Color(Item{Points: []core.Vec2{{X: 0.5, Y: 0.5}}})
+	if got.R < 0.49 || got.R > 0.51 {
+		t.Errorf("center: got %v, want mid grey", got)
+	}
+	// a configured ramp is sampled as is
+	red := core.RGBA{R: 1, A: 1}
+	ramp := palette.ColorRamp{Stops: []palette.Stop{{Pos: 0.49, Color: colors[0]}, {Pos: 0.5, Color: red}, {Pos: 0.51, Color: colors[0]}}}
+	s, err = New(config.ColorizeConfig{Strategy: ByGradient, Mode: ModeRamp}, colors, ramp, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := s.Color(Item{Points: []core.Vec2{{X: 0.5, Y: 0.5}}}); got != red {
+		t.Errorf("center with ramp: got %v, want %v", got, red)
+	}
+}
+
+func TestStrategyNoiseScale(t *testing.T) {
+	colors := []core.RGBA{{A: 1}, {R: 1, G: 1, B: 1, A: 1}}
+	// total change in color across a 20×20 grid of points
+	variation := func(scale float64) float64 {
+		s, err := New(config.ColorizeConfig{Strategy: ByNoise, Mode: ModeRamp, Space: "srgb", Scale: scale}, colors, nil, rand.New(rand.NewSource(1)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		at := func(i, j int) float64 {
+			return s.Color(Item{Points: []core.Vec2{{X: float64(i) / 19, Y: float64(j) / 19}}}).R
+		}
+		sum := 0.0
+		for j := 0; j < 20; j++ {
+			for i := 0; i < 19; i++ {
+				sum += math.Abs(at(i+1, j) - at(i, j))
+			}
+		}
+		return sum
+	}
+	low, high := variation(1), variation(8)
+	if !(high > 2*low) {
+		t.Errorf("higher scale should vary colors more: scale 1 gives %g, scale 8 gives %g", low, high)
+	}
+}
+
+func TestStrategyRandomWeights(t *testing.T) {
+	colors := []core.RGBA{{R: 1, A: 1}, {G: 1, A: 1}}
+	s, err := New(config.ColorizeConfig{Strategy: ByRandom, Weights: []float64{0, 1}}, colors, nil, rand.New(rand.NewSource(1)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 100; i++ {
+		if got := s.Color(Item{}); got != colors[1] {
+			t.Fatalf("zero-weight color picked: %v", got)
+		}
+	}
+
+	if _, err := New(config.ColorizeConfig{Strategy: ByRandom, Weights: []float64{1}}, colors, nil, nil); err == nil {
+		t.Error("expected error for mismatched weights")
+	}
+	if _, err := New(config.ColorizeConfig{Strategy: "bogus"}, colors, nil, nil); err == nil {
+		t.Error("expected error for unknown strategy")
+	}
+}
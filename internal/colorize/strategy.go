@@ -0,0 +1,236 @@
+package colorize
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+
+	"genart/internal/config"
+	"genart/internal/core"
+	"genart/internal/noise"
+	"genart/internal/palette"
+)
+
+// Item describes one scene item to a Strategy.
+type Item struct {
+	Index, Count int         // position in the scene and number of items
+	Points       []core.Vec2 // stroke path or fill polygon
+	Length       float64     // path length
+	MaxLength    float64     // longest path in the scene
+}
+
+// Strategy chooses the color of scene items, independently of how the
+// engine built them.
+type Strategy interface {
+	Color(it Item) core.RGBA
+}
+
+// Strategy names accepted in config.
+const (
+	ByNoise    = "noise"    // simplex noise at the item's centroid
+	ByGradient = "gradient" // position along a direction across the canvas
+	ByAngle    = "angle"    // direction of travel from first to last point
+	ByRadial   = "radial"   // distance of the centroid from the canvas center
+	ByIndex    = "index"    // order in the scene
+	ByLength   = "length"   // path length relative to the longest
+	ByRandom   = "random"   // random color, optionally weighted
+)
+
+// New builds the strategy described by cfg over colors. In ModeRamp it
+// samples ramp, the palette's configured ramp, or blends through colors
+// in cfg.Space when ramp is nil. rng seeds the noise and random
+// strategies.
+func New(cfg config.ColorizeConfig, colors []core.RGBA, ramp palette.Gradient, rng *rand.Rand) (Strategy, error) {
+	if len(colors) == 0 {
+		return nil, fmt.Errorf("colorize: empty palette")
+	}
+
+	if cfg.Strategy == ByRandom {
+		return newRandom(colors, cfg.Weights, rng)
+	}
+
+	var t func(it Item) float64
+	switch cfg.Strategy {
+	case ByNoise:
+		scale := cfg.Scale
+		if scale <= 0 {
+			scale = 3
+		}
+		// frequency: about scale noise features across the canvas
+		field := noise.NewSimplexField(rng.Int63(), 1)
+		t = func(it Item) float64 {
+			c := centroid(it.Points)
+			return (field.At(c.X*scale, c.Y*scale) + 1) / 2
+		}
+	case ByGradient:
+		rad := cfg.Angle * math.Pi / 180
+		dx, dy := math.Cos(rad), math.Sin(rad)
+		// project onto the direction; corners of the unit square bound the range
+		span := math.Abs(dx) + math.Abs(dy)
+		t = func(it Item) float64 {
+			c := centroid(it.Points)
+			return ((c.X-0.5)*dx+(c.Y-0.5)*dy)/span + 0.5
+		}
+	case ByAngle:
+		t = func(it Item) float64 {
+			if len(it.Points) < 2 {
+				return 0
+			}
+			a, b := it.Points[0], it.Points[len(it.Points)-1]
+			return (math.Atan2(b.Y-a.Y, b.X-a.X) + math.Pi) / (2 * math.Pi)
+		}
+	case ByRadial:
+		t = func(it Item) float64 {
+			c := centroid(it.Points)
+			return math.Hypot(c.X-0.5, c.Y-0.5) / math.Sqrt(0.5)
+		}
+	case ByIndex:
+		t = func(it Item) float64 {
+			if it.Count < 2 {
+				return 0
+			}
+			return float64(it.Index) / float64(it.Count-1)
+		}
+	case ByLength:
+		t = func(it Item) float64 {
+			if it.MaxLength == 0 {
+				return 0
+			}
+			return it.Length / it.MaxLength
+		}
+	default:
+		return nil, fmt.Errorf("colorize: unknown strategy %q", cfg.Strategy)
+	}
+
+	switch cfg.Mode {
+	case "", ModePalette:
+		return scalar{t: t, g: steps(colors)}, nil
+	case ModeRamp:
+		if ramp != nil {
+			return scalar{t: t, g: ramp}, nil
+		}
+		space, err := core.ParseColorSpace(cfg.Space, core.SpaceOKLab)
+		if err != nil {
+			return nil, err
+		}
+		return scalar{t: t, g: palette.NewRamp(colors, space)}, nil
+	default:
+		return nil, fmt.Errorf("colorize: unknown mode %q", cfg.Mode)
+	}
+}
+
+// Apply recolors every stroke and fill in scene with s. Widths and
+// item alpha are kept.
+func Apply(scene *core.Scene, s Strategy) {
+	items := make([]Item, len(scene.Items))
+	maxLen := 0.0
+	for i, it := range scene.Items {
+		var p core.Path
+		switch v := it.(type) {
+		case core.Stroke:
+			p = v.Path
+		case core.Fill:
+			p = v.Polygon
+		}
+		items[i] = Item{Index: i, Count: len(scene.Items), Points: p.Points, Length: length(p)}
+		maxLen = math.Max(maxLen, items[i].Length)
+	}
+
+	for i, it := range scene.Items {
+		items[i].MaxLength = maxLen
+		switch v := it.(type) {
+		case core.Stroke:
+			v.Color = s.Color(items[i])
+			scene.Items[i] = v
+		case core.Fill:
+			v.Color = s.Color(items[i])
+			scene.Items[i] = v
+		}
+	}
+}
+
+// scalar strategies map each item to t in [0,1] and look it up in g.
+type scalar struct {
+	t func(it Item) float64
+	g palette.Gradient
+}
+
+func (s scalar) Color(it Item) core.RGBA {
+	return s.g.At(math.Max(0, math.Min(1, s.t(it))))
+}
+
+// steps is a Gradient that splits [0,1] into len(s) equal bins, one
+// per palette entry, so every color covers the same share of t. Unlike
+// PickColorFromNoise, which scales t by len-1 and so only reaches the
+// last color at t = 1, the same t can pick a different entry here.
+type steps []core.RGBA
+
+func (s steps) At(t float64) core.RGBA {
+	return s[min(int(t*float64(len(s))), len(s)-1)]
+}
+
+// random picks colors with probability proportional to their weight.
+type random struct {
+	colors []core.RGBA
+	cum    []float64 // cumulative weights
+	rng    *rand.Rand
+}
+
+func newRandom(colors []core.RGBA, weights []float64, rng *rand.Rand) (*random, error) {
+	if len(weights) > 0 && len(weights) != len(colors) {
+		return nil, fmt.Errorf("colorize: %d weights for %d colors", len(weights), len(colors))
+	}
+	r := &random{colors: colors, cum: make([]float64, len(colors)), rng: rng}
+	sum := 0.0
+	for i := range colors {
+		w := 1.0
+		if len(weights) > 0 {
+			w = weights[i]
+		}
+		if w < 0 || math.IsNaN(w) {
+			return nil, fmt.Errorf("colorize: invalid weight %g", w)
+		}
+		sum += w
+		r.cum[i] = sum
+	}
+	if sum == 0 {
+		return nil, fmt.Errorf("colorize: weights sum to zero")
+	}
+	return r, nil
+}
+
+func (r *random) Color(Item) core.RGBA {
+	v := r.rng.Float64() * r.cum[len(r.cum)-1]
+	for i, c := range r.cum {
+		if v < c {
+			return r.colors[i]
+		}
+	}
+	return r.colors[len(r.colors)-1]
+}
+
+func centroid(pts []core.Vec2) core.Vec2 {
+	if len(pts) == 0 {
+		return core.Vec2{X: 0.5, Y: 0.5}
+	}
+	var c core.Vec2
+	for _, p := range pts {
+		c.X += p.X
+		c.Y += p.Y
+	}
+	n := float64(len(pts))
+	return core.Vec2{X: c.X / n, Y: c.Y / n}
+}
+
+func length(p core.Path) float64 {
+	d := 0.0
+	for i := 1; i < len(p.Points); i++ {
+		a, b := p.Points[i-1], p.Points[i]
+		d += math.Hypot(b.X-a.X, b.Y-a.Y)
+	}
+	if p.Closed && len(p.Points) > 2 {
+		a, b := p.Points[len(p.Points)-1], p.Points[0]
+		d += math.Hypot(b.X-a.X, b.Y-a.Y)
+	}
+	return d
+}
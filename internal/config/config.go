@@ -1,6 +1,10 @@
 package config
 
-import "genart/internal/core"
+import (
+	"encoding/json"
+
+	"genart/internal/core"
+)
 
 // Config represents a full run configuration.
 // This is both the INPUT (when loaded from file/string)
@@ -15,29 +19,140 @@ type Config struct {
 	Palette    PaletteConfig      `json:"palette"`
 	Params     map[string]float64 `json:"params"`
 
+	// ParamNames holds params given by option name in the input, such
+	// as "noise": "fbm"; core.ResolveNames moves them into Params.
+	ParamNames map[string]string `json:"-"`
+
+	Field     *FieldConfig     `json:"field,omitempty"`
+	Colorize  *ColorizeConfig  `json:"colorize,omitempty"`
 	Render    RenderConfig     `json:"render"`
 	Animation *AnimationConfig `json:"animation,omitempty"`
+	Plot      *PlotConfig      `json:"plot,omitempty"`
 }
 
 // PaletteConfig controls palette generation.
 type PaletteConfig struct {
-	Type string    `json:"type"` // e.g. "mono"
+	Type string    `json:"type"` // generator ("mono", "warm", ...), "colors", "cosine", "image" or "file"
 	Base core.RGBA `json:"base"` // base color
 	N    int       `json:"n"`    // number of colors
+
+	Space string `json:"space,omitempty"` // generation space: "hsl" (default) or "oklch"; "srgb" or "oklab" for images
+
+	// types "colors", "image" and "file"
+	Colors []core.RGBA `json:"colors,omitempty"` // explicit colors ([r,g,b,a] or "#rrggbb"); image and file results are recorded here
+	Source string      `json:"source,omitempty"` // image, or .gpl/.ase/.txt/.hex palette file; unused once Colors is set
+	Method string      `json:"method,omitempty"` // image: "kmeans" (default) or "median-cut"
+	Sort   string      `json:"sort,omitempty"`   // image: "lightness" (default) or "frequency"
+
+	Cosine *CosineConfig `json:"cosine,omitempty"` // type "cosine"
+	Ramp   *RampConfig   `json:"ramp,omitempty"`   // smooth the palette into a ramp
+}
+
+// CosineConfig defines a cosine gradient palette
+// color(t) = a + b·cos(2π(c·t + d)), each vector holding r, g, b.
+type CosineConfig struct {
+	A [3]float64 `json:"a"`
+	B [3]float64 `json:"b"`
+	C [3]float64 `json:"c"`
+	D [3]float64 `json:"d"`
+}
+
+// RampConfig turns a palette into a smooth ramp through its colors.
+// Ramp coloring samples the ramp continuously; everything else gets
+// Samples colors spread along it.
+type RampConfig struct {
+	Positions []float64 `json:"positions,omitempty"` // stop position per color in [0,1]; evenly spaced when empty
+	Space     string    `json:"space,omitempty"`     // interpolation space, "oklab" (default), "oklch", "lab", "linear" or "srgb"
+	Samples   int       `json:"samples,omitempty"`   // colors in the result (default 32)
+}
+
+// FieldConfig replaces the seeded noise of engines that accept a field
+// with one read from an image or computed by a formula. If nil, engines
+// use their own noise. A plain string is shorthand for {"expr": ...}.
+type FieldConfig struct {
+	Expr string `json:"expr,omitempty"` // formula over x and y, e.g. "sin(10*x) + 0.5*simplex(x*4, y*4)"
+
+	Image   string  `json:"image,omitempty"`   // PNG or JPEG path
+	Channel string  `json:"channel,omitempty"` // "luminance" (default), "red", "green", "blue", "alpha", "hue" or "edges"
+	Fit     string  `json:"fit,omitempty"`     // "stretch" (default), "contain" or "cover"
+	Blur    float64 `json:"blur,omitempty"`    // blur radius in image pixels
+	Invert  bool    `json:"invert,omitempty"`  // dark areas read high
+}
+
+// UnmarshalJSON accepts a formula string as well as an object.
+func (f *FieldConfig) UnmarshalJSON(data []byte) error {
+	var expr string
+	if err := json.Unmarshal(data, &expr); err == nil {
+		*f = FieldConfig{Expr: expr}
+		return nil
+	}
+	type plain FieldConfig // without this method
+	return json.Unmarshal(data, (*plain)(f))
+}
+
+// ColorizeConfig recolors the generated scene, replacing the engine's
+// own color choices. If nil, engine colors are kept.
+type ColorizeConfig struct {
+	Strategy string    `json:"strategy"`          // "noise", "gradient", "angle", "radial", "index", "length" or "random"
+	Mode     string    `json:"mode,omitempty"`    // "palette" (default) snaps to colors, "ramp" blends between them
+	Space    string    `json:"space,omitempty"`   // ramp interpolation space (default "oklab"), unless the palette configures a ramp
+	Scale    float64   `json:"scale,omitempty"`   // noise: frequency, roughly features across the canvas (default 3)
+	Angle    float64   `json:"angle,omitempty"`   // gradient: direction in degrees
+	Weights  []float64 `json:"weights,omitempty"` // random: relative weight per palette color (default equal)
 }
 
 // RenderConfig controls renderer settings.
 type RenderConfig struct {
+	Backend     string  `json:"backend,omitempty"` // "gg" (default) or "svg"; inferred from out when empty
 	Margin      float64 `json:"margin"`
 	Supersample int     `json:"supersample"`
+	Filter      string  `json:"filter,omitempty"` // "box" (default) or "lanczos"
+
+	// Tile draws items crossing an edge again on the opposite side so
+	// the image repeats seamlessly; needs margin 0 and works best with
+	// an engine's "tile" param. TilePreview, if set, is a PNG path for
+	// a 2×2 repeat of the output.
+	Tile        bool   `json:"tile,omitempty"`
+	TilePreview string `json:"tile_preview,omitempty"`
 }
 
 // AnimationConfig controls animation runs.
 // If nil, the run is static (PNG).
 type AnimationConfig struct {
-	Duration float64        `json:"duration"` // seconds
-	FPS      int            `json:"fps"`
-	Vary     map[string]any `json:"vary,omitempty"`   // param name -> [start,end]
-	Easing   string         `json:"easing,omitempty"` // "linear" (default), "cosine", "sin"
-	LogFrames bool          `json:"log_frames,omitempty"`
+	Duration   float64        `json:"duration"` // seconds
+	FPS        int            `json:"fps"`
+	Vary       map[string]any `json:"vary,omitempty"`   // param, "palette", "palette.base", "palette.n" or "bg" -> [start,end] or keyframe list
+	Easing     string         `json:"easing,omitempty"` // default segment easing: "linear", "cosine", "sin", "ease-in", ...
+	LogFrames  bool           `json:"log_frames,omitempty"`
+	Workers    int            `json:"workers,omitempty"`     // frames rendered in parallel; 0 = one per CPU
+	Loop       bool           `json:"loop,omitempty"`        // fixed seed, engine walks a closed loop through noise
+	ColorSpace string         `json:"color_space,omitempty"` // blend colors in "srgb", "linear", "oklab", "oklch" or "lab"
+	Format     string         `json:"format,omitempty"`      // "gif", "apng" or "frames" (PNG directory); inferred from out when empty
+
+	// GIF encoding
+	Quantize     string `json:"quantize,omitempty"`      // "median-cut" (default) or "plan9"
+	PaletteScope string `json:"palette_scope,omitempty"` // "global" (default) or "frame"
+	Dither       string `json:"dither,omitempty"`        // "floyd-steinberg" (default), "bayer" or "none"
+	Delta        bool   `json:"delta,omitempty"`         // store only changed pixels of each frame
+}
+
+// PlotConfig controls pen-plotter export.
+// If nil, the run renders an image instead.
+// Lengths are in millimetres on the physical paper.
+type PlotConfig struct {
+	Format       string  `json:"format,omitempty"`        // "svg", "hpgl" or "gcode"; inferred from out when empty
+	Paper        string  `json:"paper,omitempty"`         // "A5", "A4" (default), "A3", "letter"
+	PaperWidth   float64 `json:"paper_width,omitempty"`   // overrides Paper when both sizes are set
+	PaperHeight  float64 `json:"paper_height,omitempty"`  // overrides Paper when both sizes are set
+	Landscape    bool    `json:"landscape,omitempty"`     // put the long side of the paper across, named or explicit
+	Margin       float64 `json:"margin,omitempty"`        // blank border around the drawing
+	PenWidth     float64 `json:"pen_width,omitempty"`     // stroke width in the SVG preview
+	Fills        string  `json:"fills,omitempty"`         // "drop" (default) or "hatch"
+	HatchSpacing float64 `json:"hatch_spacing,omitempty"` // distance between hatch lines
+	HatchAngle   float64 `json:"hatch_angle,omitempty"`   // degrees
+	Tolerance    float64 `json:"tolerance,omitempty"`     // max gap when joining segments into polylines
+	Optimize     *bool   `json:"optimize,omitempty"`      // reorder paths to minimize pen-up travel (default true)
+	Feed         float64 `json:"feed,omitempty"`          // G-code drawing feed rate, mm/min
+	PenUp        string  `json:"pen_up,omitempty"`        // G-code command lifting the pen
+	PenDown      string  `json:"pen_down,omitempty"`      // G-code command lowering the pen
 }
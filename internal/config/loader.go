@@ -2,6 +2,7 @@ package config
 
 import (
 	"encoding/json"
+	"fmt"
 	"os"
 	"strings"
 )
@@ -22,10 +23,47 @@ func Load(input string) (*Config, error) {
 		data = b
 	}
 
+	// params are decoded separately since they may be numbers, booleans
+	// or enum option names
 	var cfg Config
-	if err := json.Unmarshal(data, &cfg); err != nil {
+	raw := struct {
+		*Config
+		Params map[string]any `json:"params"`
+	}{Config: &cfg}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	if err := cfg.setParams(raw.Params); err != nil {
 		return nil, err
 	}
 
 	return &cfg, nil
 }
+
+// setParams splits raw params into numeric Params (booleans as 0 or 1)
+// and ParamNames.
+func (c *Config) setParams(raw map[string]any) error {
+	if raw == nil {
+		return nil
+	}
+	c.Params = make(map[string]float64, len(raw))
+	for k, v := range raw {
+		switch v := v.(type) {
+		case float64:
+			c.Params[k] = v
+		case bool:
+			c.Params[k] = 0
+			if v {
+				c.Params[k] = 1
+			}
+		case string:
+			if c.ParamNames == nil {
+				c.ParamNames = make(map[string]string)
+			}
+			c.ParamNames[k] = v
+		default:
+			return fmt.Errorf("param %q: want a number, boolean or option name", k)
+		}
+	}
+	return nil
+}
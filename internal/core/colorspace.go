@@ -0,0 +1,249 @@
+package core
+
+import (
+	"fmt"
+	"math"
+)
+
+// ColorSpace names a space colors can be converted to and
+// interpolated in.
+type ColorSpace string
+
+const (
+	SpaceSRGB   ColorSpace = "srgb"   // gamma-encoded r,g,b as stored in RGBA
+	SpaceLinear ColorSpace = "linear" // linear-light r,g,b
+	SpaceHSL    ColorSpace = "hsl"    // only for palette generation
+	SpaceOKLab  ColorSpace = "oklab"  // L in [0,1], a and b roughly [-0.4,0.4]
+	SpaceOKLCH  ColorSpace = "oklch"  // L in [0,1], chroma, hue in degrees
+	SpaceLab    ColorSpace = "lab"    // CIELAB (D65), L in [0,100]
+)
+
+// ParseColorSpace validates an interpolation space name. An empty name
+// returns def.
+func ParseColorSpace(name string, def ColorSpace) (ColorSpace, error) {
+	switch s := ColorSpace(name); s {
+	case "":
+		return def, nil
+	case SpaceSRGB, SpaceLinear, SpaceOKLab, SpaceOKLCH, SpaceLab:
+		return s, nil
+	default:
+		return "", fmt.Errorf("unknown color space %q (available: srgb, linear, oklab, oklch, lab)", name)
+	}
+}
+
+// In returns c's coordinates in space, alpha excluded.
+// Unknown spaces (and hsl) return sRGB.
+func (c RGBA) In(space ColorSpace) [3]float64 {
+	switch space {
+	case SpaceLinear:
+		r, g, b := c.Linear()
+		return [3]float64{r, g, b}
+	case SpaceOKLab:
+		l, a, b := c.OKLab()
+		return [3]float64{l, a, b}
+	case SpaceOKLCH:
+		l, ch, h := c.OKLCH()
+		return [3]float64{l, ch, h}
+	case SpaceLab:
+		l, a, b := c.Lab()
+		return [3]float64{l, a, b}
+	default:
+		return [3]float64{c.R, c.G, c.B}
+	}
+}
+
+// FromSpace is the inverse of In.
+func FromSpace(space ColorSpace, v [3]float64, alpha float64) RGBA {
+	switch space {
+	case SpaceLinear:
+		return FromLinear(v[0], v[1], v[2], alpha)
+	case SpaceOKLab:
+		return FromOKLab(v[0], v[1], v[2], alpha)
+	case SpaceOKLCH:
+		return FromOKLCH(v[0], v[1], v[2], alpha)
+	case SpaceLab:
+		return FromLab(v[0], v[1], v[2], alpha)
+	default:
+		return RGBA{R: v[0], G: v[1], B: v[2], A: alpha}
+	}
+}
+
+// Mix interpolates from c to d by t in space. In OKLCH the hue takes
+// the shorter way around the circle.
+func (c RGBA) Mix(d RGBA, t float64, space ColorSpace) RGBA {
+	// keep the ends exact rather than round-tripping them
+	if t == 0 {
+		return c
+	}
+	if t == 1 {
+		return d
+	}
+	p, q := c.In(space), d.In(space)
+	if space == SpaceOKLCH {
+		q[2] = NearestHue(p[2], q[2])
+	}
+	var v [3]float64
+	for i := range v {
+		v[i] = p[i] + (q[i]-p[i])*t
+	}
+	return FromSpace(space, v, c.A+(d.A-c.A)*t)
+}
+
+// NearestHue returns h shifted by whole turns to lie within 180° of
+// ref, so interpolating from ref to it takes the short way round.
+func NearestHue(ref, h float64) float64 {
+	for h-ref > 180 {
+		h -= 360
+	}
+	for ref-h > 180 {
+		h += 360
+	}
+	return h
+}
+
+// Linear returns c with the sRGB transfer curve removed.
+func (c RGBA) Linear() (r, g, b float64) {
+	return srgbToLinear(c.R), srgbToLinear(c.G), srgbToLinear(c.B)
+}
+
+// FromLinear encodes linear-light channels as sRGB, clipping to [0,1].
+func FromLinear(r, g, b, alpha float64) RGBA {
+	return RGBA{R: linearToSRGB(r), G: linearToSRGB(g), B: linearToSRGB(b), A: alpha}
+}
+
+// OKLab returns the color in the OKLab perceptual space, where equal
+// distances look roughly equally different. L is in [0,1].
+func (c RGBA) OKLab() (l, a, b float64) {
+	r, g, bl := c.Linear()
+
+	lc := math.Cbrt(0.4122214708*r + 0.5363325363*g + 0.0514459929*bl)
+	mc := math.Cbrt(0.2119034982*r + 0.6806995451*g + 0.1073969566*bl)
+	sc := math.Cbrt(0.0883024619*r + 0.2817188376*g + 0.6299787005*bl)
+
+	l = 0.2104542553*lc + 0.7936177850*mc - 0.0040720468*sc
+	a = 1.9779984951*lc - 2.4285922050*mc + 0.4505937099*sc
+	b = 0.0259040371*lc + 0.7827717662*mc - 0.8086757660*sc
+	return
+}
+
+// FromOKLab converts an OKLab color back to sRGB, clipping colors
+// outside the sRGB gamut.
+func FromOKLab(l, a, b, alpha float64) RGBA {
+	r, g, bl := okLabToLinear(l, a, b)
+	return FromLinear(r, g, bl, alpha)
+}
+
+// OKLCH returns the color in polar OKLab: lightness, chroma and hue in
+// degrees [0,360).
+func (c RGBA) OKLCH() (l, ch, h float64) {
+	l, a, b := c.OKLab()
+	ch = math.Hypot(a, b)
+	h = math.Mod(math.Atan2(b, a)*180/math.Pi+360, 360)
+	return
+}
+
+// FromOKLCH converts polar OKLab back to sRGB, clipping colors outside
+// the sRGB gamut.
+func FromOKLCH(l, ch, h, alpha float64) RGBA {
+	rad := h * math.Pi / 180
+	return FromOKLab(l, ch*math.Cos(rad), ch*math.Sin(rad), alpha)
+}
+
+// InGamutOKLCH reports whether the OKLCH color is displayable in sRGB
+// without clipping.
+func InGamutOKLCH(l, ch, h float64) bool {
+	const eps = 1e-6
+	rad := h * math.Pi / 180
+	r, g, b := okLabToLinear(l, ch*math.Cos(rad), ch*math.Sin(rad))
+	for _, v := range []float64{r, g, b} {
+		if v < -eps || v > 1+eps {
+			return false
+		}
+	}
+	return true
+}
+
+// D65 reference white for CIELAB.
+const (
+	whiteX = 0.95047
+	whiteY = 1.0
+	whiteZ = 1.08883
+)
+
+// Lab returns the CIELAB coordinates of c (D65 white): L in [0,100],
+// a and b roughly [-128,127].
+func (c RGBA) Lab() (l, a, b float64) {
+	r, g, bl := c.Linear()
+	x := (0.4124564*r + 0.3575761*g + 0.1804375*bl) / whiteX
+	y := (0.2126729*r + 0.7151522*g + 0.0721750*bl) / whiteY
+	z := (0.0193339*r + 0.1191920*g + 0.9503041*bl) / whiteZ
+
+	fx, fy, fz := labF(x), labF(y), labF(z)
+	return 116*fy - 16, 500 * (fx - fy), 200 * (fy - fz)
+}
+
+// FromLab converts CIELAB back to sRGB, clipping colors outside the
+// sRGB gamut.
+func FromLab(l, a, b, alpha float64) RGBA {
+	fy := (l + 16) / 116
+	fx := fy + a/500
+	fz := fy - b/200
+	x, y, z := labFInv(fx)*whiteX, labFInv(fy)*whiteY, labFInv(fz)*whiteZ
+
+	return FromLinear(
+		3.2404542*x-1.5371385*y-0.4985314*z,
+		-0.9692660*x+1.8760108*y+0.0415560*z,
+		0.0556434*x-0.2040259*y+1.0572252*z,
+		alpha,
+	)
+}
+
+func labF(t float64) float64 {
+	const d = 6.0 / 29
+	if t > d*d*d {
+		return math.Cbrt(t)
+	}
+	return t/(3*d*d) + 4.0/29
+}
+
+func labFInv(t float64) float64 {
+	const d = 6.0 / 29
+	if t > d {
+		return t * t * t
+	}
+	return 3 * d * d * (t - 4.0/29)
+}
+
+func okLabToLinear(l, a, b float64) (r, g, bl float64) {
+	lc := l + 0.3963377774*a + 0.2158037573*b
+	mc := l - 0.1055613458*a - 0.0638541728*b
+	sc := l - 0.0894841775*a - 1.2914855480*b
+	lc, mc, sc = lc*lc*lc, mc*mc*mc, sc*sc*sc
+
+	r = +4.0767416621*lc - 3.3077115913*mc + 0.2309699292*sc
+	g = -1.2684380046*lc + 2.6097574011*mc - 0.3413193965*sc
+	bl = -0.0041960863*lc - 0.7034186147*mc + 1.7076147010*sc
+	return
+}
+
+// srgbToLinear removes the sRGB transfer curve from a channel in [0,1].
+func srgbToLinear(v float64) float64 {
+	if v <= 0.04045 {
+		return v / 12.92
+	}
+	return math.Pow((v+0.055)/1.055, 2.4)
+}
+
+// linearToSRGB applies the sRGB transfer curve and clips to [0,1].
+func linearToSRGB(v float64) float64 {
+	if v <= 0 {
+		return 0
+	}
+	if v >= 1 {
+		return 1
+	}
+	if v <= 0.0031308 {
+		return v * 12.92
+	}
+	return 1.055*math.Pow(v, 1/2.4) - 0.055
+}
@@ -3,6 +3,7 @@ package core
 import (
 	"context"
 	"image"
+	"io"
 	"math/rand"
 )
 
@@ -18,7 +19,6 @@ type Path struct {
 	Closed bool
 }
 
-
 // A marker interface for things that can be drawn in a Scene.
 type Item interface{ isItem() }
 
@@ -52,12 +52,30 @@ type Engine interface {
 	Generate(ctx context.Context, rng *rand.Rand, params map[string]float64, colors []RGBA) (Scene, error)
 }
 
+// Generates one phase of a seamlessly looping animation.
+// t in [0,1) walks a closed loop through noise space, so t=1 would
+// reproduce t=0. The rng is seeded identically for every phase.
+type Looper interface {
+	Engine
+	GenerateLoop(ctx context.Context, rng *rand.Rand, params map[string]float64, colors []RGBA, t float64) (Scene, error)
+}
+
+// Replaces its seeded noise with a custom field (the config's "field")
+// when one is carried by the context passed to Generate; see
+// noise.WithField. Runs reject a field for engines without it.
+type FieldUser interface {
+	Engine
+	AcceptsField()
+}
+
 // Controls how a Scene is mapped into pixels.
 type RenderConfig struct {
 	Width, Height int
 	Background    RGBA
 	Margin        float64 // fraction of min(width,height)
-	Supersample   int
+	Supersample   int     // render at this multiple of Width×Height, then downsample
+	Filter        string  // downsampling filter: "box" (default) or "lanczos"
+	Tile          bool    // repeat items crossing an edge on the opposite side
 	Palette       []RGBA
 }
 
@@ -66,3 +84,9 @@ type Renderer interface {
 	Name() string
 	Render(scene Scene, cfg RenderConfig) (image.Image, error)
 }
+
+// Writes a Scene as a resolution-independent vector document.
+type VectorRenderer interface {
+	Renderer
+	Encode(w io.Writer, scene Scene, cfg RenderConfig) error
+}
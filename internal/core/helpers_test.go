@@ -1,6 +1,9 @@
 package core
 
-import "testing"
+import (
+	"math"
+	"testing"
+)
 
 func TestNewStrokeAndFill(t *testing.T) {
 	scene := Scene{}
@@ -27,3 +30,33 @@ func TestNewStrokeAndFill(t *testing.T) {
 		t.Errorf("expected closed polygon, got open")
 	}
 }
+
+func TestColorSpaceRoundTrip(t *testing.T) {
+	l, a, b := RGBA{R: 1, G: 1, B: 1, A: 1}.OKLab()
+	if math.Abs(l-1) > 1e-4 || math.Abs(a) > 1e-4 || math.Abs(b) > 1e-4 {
+		t.Errorf("white in OKLab = (%g, %g, %g), want (1, 0, 0)", l, a, b)
+	}
+	l, a, b = RGBA{R: 1, G: 1, B: 1, A: 1}.Lab()
+	if math.Abs(l-100) > 1e-3 || math.Abs(a) > 1e-3 || math.Abs(b) > 1e-3 {
+		t.Errorf("white in Lab = (%g, %g, %g), want (100, 0, 0)", l, a, b)
+	}
+
+	colors := []RGBA{{R: 0.8, G: 0.2, B: 0.1, A: 1}, {R: 0.1, G: 0.5, B: 0.9, A: 0.5}, {A: 1}}
+	for _, space := range []ColorSpace{SpaceSRGB, SpaceLinear, SpaceOKLab, SpaceOKLCH, SpaceLab} {
+		for _, c := range colors {
+			got := FromSpace(space, c.In(space), c.A)
+			if math.Abs(got.R-c.R) > 1e-6 || math.Abs(got.G-c.G) > 1e-6 || math.Abs(got.B-c.B) > 1e-6 || got.A != c.A {
+				t.Errorf("%s: round trip %v → %v", space, c, got)
+			}
+		}
+	}
+}
+
+func TestMixOKLCHShortHue(t *testing.T) {
+	// red (~29°) to magenta (~328°) should pass through pink, not green
+	red, magenta := RGBA{R: 1, A: 1}, RGBA{R: 1, B: 1, A: 1}
+	_, _, h := red.Mix(magenta, 0.5, SpaceOKLCH).OKLCH()
+	if h > 30 && h < 320 {
+		t.Errorf("midpoint hue = %g, want between magenta and red", h)
+	}
+}
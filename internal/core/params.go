@@ -0,0 +1,212 @@
+package core
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// ParamType is the kind of value an engine parameter holds.
+type ParamType string
+
+const (
+	ParamFloat ParamType = "float"
+	ParamInt   ParamType = "int"
+	ParamBool  ParamType = "bool"  // 0 = false, 1 = true
+	ParamEnum  ParamType = "enum"  // index into Options
+	ParamColor ParamType = "color" // keys name.r, name.g, name.b, name.a in [0,1]
+	ParamVec2  ParamType = "vec2"  // keys name.x, name.y
+)
+
+// Param describes one engine parameter.
+// Params travel as map[string]float64, so multi-component types
+// (color, vec2) occupy one key per component; see Keys.
+type Param struct {
+	Name        string
+	Type        ParamType
+	Default     []float64 // one value per key
+	Min, Max    float64   // inclusive bounds for every component
+	Options     []string  // enum choices
+	Description string
+}
+
+// Keys returns the param map keys this Param occupies.
+func (p Param) Keys() []string {
+	switch p.Type {
+	case ParamColor:
+		return []string{p.Name + ".r", p.Name + ".g", p.Name + ".b", p.Name + ".a"}
+	case ParamVec2:
+		return []string{p.Name + ".x", p.Name + ".y"}
+	default:
+		return []string{p.Name}
+	}
+}
+
+// check validates a single component value.
+func (p Param) check(key string, v float64) error {
+	if math.IsNaN(v) {
+		return fmt.Errorf("param %q is NaN", key)
+	}
+	if v < p.Min || v > p.Max {
+		return fmt.Errorf("param %q = %g out of range [%g, %g]", key, v, p.Min, p.Max)
+	}
+	switch p.Type {
+	case ParamInt, ParamBool, ParamEnum:
+		if v != math.Trunc(v) {
+			return fmt.Errorf("param %q = %g must be a whole number", key, v)
+		}
+	}
+	return nil
+}
+
+// Constructors for the common parameter types.
+// Use math.Inf for an open bound.
+
+func FloatParam(name string, def, min, max float64, desc string) Param {
+	return Param{Name: name, Type: ParamFloat, Default: []float64{def}, Min: min, Max: max, Description: desc}
+}
+
+func IntParam(name string, def, min, max int, desc string) Param {
+	return Param{Name: name, Type: ParamInt, Default: []float64{float64(def)}, Min: float64(min), Max: float64(max), Description: desc}
+}
+
+func BoolParam(name string, def bool, desc string) Param {
+	d := 0.0
+	if def {
+		d = 1
+	}
+	return Param{Name: name, Type: ParamBool, Default: []float64{d}, Min: 0, Max: 1, Description: desc}
+}
+
+func EnumParam(name string, def string, options []string, desc string) Param {
+	idx := 0
+	for i, o := range options {
+		if o == def {
+			idx = i
+		}
+	}
+	return Param{Name: name, Type: ParamEnum, Default: []float64{float64(idx)}, Min: 0, Max: float64(len(options) - 1), Options: options, Description: desc}
+}
+
+func ColorParam(name string, def RGBA, desc string) Param {
+	return Param{Name: name, Type: ParamColor, Default: []float64{def.R, def.G, def.B, def.A}, Min: 0, Max: 1, Description: desc}
+}
+
+func Vec2Param(name string, def Vec2, min, max float64, desc string) Param {
+	return Param{Name: name, Type: ParamVec2, Default: []float64{def.X, def.Y}, Min: min, Max: max, Description: desc}
+}
+
+// ParamSpec lists every parameter an engine accepts.
+type ParamSpec []Param
+
+// Describer is implemented by engines that declare their parameters.
+type Describer interface {
+	Describe() ParamSpec
+}
+
+// Validate rejects unknown keys and out-of-range or ill-typed values.
+func (s ParamSpec) Validate(params map[string]float64) error {
+	known := make(map[string]Param)
+	for _, p := range s {
+		for _, k := range p.Keys() {
+			known[k] = p
+		}
+	}
+
+	// sorted for stable error messages
+	keys := make([]string, 0, len(params))
+	for k := range params {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		p, ok := known[k]
+		if !ok {
+			return fmt.Errorf("unknown param %q (accepted: %s)", k, strings.Join(s.keys(), ", "))
+		}
+		if err := p.check(k, params[k]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WithDefaults returns a copy of params with every missing key
+// filled in from the spec defaults.
+func (s ParamSpec) WithDefaults(params map[string]float64) map[string]float64 {
+	out := make(map[string]float64, len(params))
+	for k, v := range params {
+		out[k] = v
+	}
+	for _, p := range s {
+		for i, k := range p.Keys() {
+			if _, ok := out[k]; !ok {
+				out[k] = p.Default[i]
+			}
+		}
+	}
+	return out
+}
+
+func (s ParamSpec) keys() []string {
+	out := make([]string, 0, len(s))
+	for _, p := range s {
+		out = append(out, p.Keys()...)
+	}
+	return out
+}
+
+// ResolveParams validates params against eng's spec and returns them
+// with defaults filled in. Engines without a spec get params unchanged.
+func ResolveParams(eng Engine, params map[string]float64) (map[string]float64, error) {
+	d, ok := eng.(Describer)
+	if !ok {
+		return params, nil
+	}
+	spec := d.Describe()
+	if err := spec.Validate(params); err != nil {
+		return nil, fmt.Errorf("%s: %w", eng.Name(), err)
+	}
+	return spec.WithDefaults(params), nil
+}
+
+// ResolveNames returns a copy of params with enum params given by
+// option name in names converted to their index.
+func ResolveNames(eng Engine, params map[string]float64, names map[string]string) (map[string]float64, error) {
+	out := make(map[string]float64, len(params)+len(names))
+	for k, v := range params {
+		out[k] = v
+	}
+	if len(names) == 0 {
+		return out, nil
+	}
+
+	var spec ParamSpec
+	if d, ok := eng.(Describer); ok {
+		spec = d.Describe()
+	}
+	for k, name := range names {
+		p, ok := spec.lookup(k)
+		if !ok || p.Type != ParamEnum {
+			return nil, fmt.Errorf("%s: param %q does not take an option name", eng.Name(), k)
+		}
+		i := slices.Index(p.Options, name)
+		if i < 0 {
+			return nil, fmt.Errorf("%s: param %q: unknown option %q (available: %s)", eng.Name(), k, name, strings.Join(p.Options, ", "))
+		}
+		out[k] = float64(i)
+	}
+	return out, nil
+}
+
+func (s ParamSpec) lookup(name string) (Param, bool) {
+	for _, p := range s {
+		if p.Name == name {
+			return p, true
+		}
+	}
+	return Param{}, false
+}
@@ -0,0 +1,85 @@
+package core
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestParamSpecValidate(t *testing.T) {
+	spec := ParamSpec{
+		IntParam("count", 10, 1, 100, ""),
+		FloatParam("lw", 0.001, 0, 1, ""),
+		EnumParam("mode", "b", []string{"a", "b", "c"}, ""),
+		Vec2Param("center", Vec2{X: 0.5, Y: 0.5}, 0, 1, ""),
+	}
+
+	if err := spec.Validate(map[string]float64{"count": 5, "lw": 0.5, "mode": 2, "center.x": 0.1}); err != nil {
+		t.Fatalf("expected valid params, got %v", err)
+	}
+
+	bad := []map[string]float64{
+		{"nope": 1},
+		{"count": 0},
+		{"count": 2.5},
+		{"lw": math.NaN()},
+		{"mode": 3},
+		{"center.y": 2},
+	}
+	for _, p := range bad {
+		if err := spec.Validate(p); err == nil {
+			t.Errorf("expected error for %v", p)
+		}
+	}
+}
+
+func TestParamSpecWithDefaults(t *testing.T) {
+	spec := ParamSpec{
+		IntParam("count", 10, 1, 100, ""),
+		ColorParam("ink", RGBA{R: 1, A: 1}, ""),
+	}
+	in := map[string]float64{"count": 3}
+	out := spec.WithDefaults(in)
+
+	if out["count"] != 3 {
+		t.Errorf("explicit value overwritten: %v", out["count"])
+	}
+	if out["ink.r"] != 1 || out["ink.a"] != 1 || out["ink.g"] != 0 {
+		t.Errorf("color defaults not filled: %v", out)
+	}
+	if len(in) != 1 {
+		t.Errorf("input map was modified")
+	}
+}
+
+type specEngine ParamSpec
+
+func (specEngine) Name() string { return "test" }
+
+func (e specEngine) Describe() ParamSpec { return ParamSpec(e) }
+
+func (specEngine) Generate(context.Context, *rand.Rand, map[string]float64, []RGBA) (Scene, error) {
+	return Scene{}, nil
+}
+
+func TestResolveNames(t *testing.T) {
+	eng := specEngine{
+		IntParam("count", 10, 1, 100, ""),
+		EnumParam("mode", "a", []string{"a", "b", "c"}, ""),
+	}
+
+	out, err := ResolveNames(eng, map[string]float64{"count": 3}, map[string]string{"mode": "c"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out["mode"] != 2 || out["count"] != 3 {
+		t.Errorf("got %v", out)
+	}
+
+	for _, names := range []map[string]string{{"mode": "d"}, {"count": "a"}, {"nope": "a"}} {
+		if _, err := ResolveNames(eng, nil, names); err == nil {
+			t.Errorf("expected error for %v", names)
+		}
+	}
+}
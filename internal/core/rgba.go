@@ -3,6 +3,8 @@ package core
 import (
 	"encoding/json"
 	"fmt"
+	"strconv"
+	"strings"
 )
 
 // A color with float components in [0,1].
@@ -10,10 +12,49 @@ type RGBA struct {
 	R, G, B, A float64
 }
 
-// UnmarshalJSON allows RGBA to be decoded from either
-// an array [r,g,b,a] or an object {"R":..,"G":..,"B":..,"A":..}.
+// ParseHex parses "#rgb", "#rgba", "#rrggbb" or "#rrggbbaa"; the "#"
+// is optional.
+func ParseHex(s string) (RGBA, error) {
+	h := strings.TrimPrefix(strings.TrimSpace(s), "#")
+	if len(h) == 3 || len(h) == 4 {
+		long := make([]byte, 0, 8)
+		for i := 0; i < len(h); i++ {
+			long = append(long, h[i], h[i])
+		}
+		h = string(long)
+	}
+	if len(h) == 6 {
+		h += "ff"
+	}
+	if len(h) != 8 {
+		return RGBA{}, fmt.Errorf("invalid hex color %q", s)
+	}
+	v, err := strconv.ParseUint(h, 16, 32)
+	if err != nil {
+		return RGBA{}, fmt.Errorf("invalid hex color %q", s)
+	}
+	return RGBA{
+		R: float64(v>>24&0xff) / 255,
+		G: float64(v>>16&0xff) / 255,
+		B: float64(v>>8&0xff) / 255,
+		A: float64(v&0xff) / 255,
+	}, nil
+}
+
+// UnmarshalJSON allows RGBA to be decoded from an array [r,g,b,a],
+// a hex string "#rrggbb" or an object {"R":..,"G":..,"B":..,"A":..}.
 func (c *RGBA) UnmarshalJSON(data []byte) error {
-	// Try array form first
+	var hex string
+	if err := json.Unmarshal(data, &hex); err == nil {
+		v, err := ParseHex(hex)
+		if err != nil {
+			return err
+		}
+		*c = v
+		return nil
+	}
+
+	// Try array form
 	var arr []float64
 	if err := json.Unmarshal(data, &arr); err == nil {
 		if len(arr) < 3 || len(arr) > 4 {
@@ -0,0 +1,15 @@
+// Package all imports every engine so each registers itself with
+// registry.Engines. Import it for side effects:
+//
+//	import _ "genart/internal/engines/all"
+package all
+
+import (
+	_ "genart/internal/engines/blackhole"
+	_ "genart/internal/engines/contourlines"
+	_ "genart/internal/engines/flow"
+	_ "genart/internal/engines/flowfield"
+	_ "genart/internal/engines/perlinpearls"
+	_ "genart/internal/engines/strata"
+	_ "genart/internal/engines/swirl"
+)
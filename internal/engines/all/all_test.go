@@ -0,0 +1,132 @@
+package all
+
+import (
+	"context"
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"genart/internal/core"
+	"genart/internal/noise"
+	"genart/internal/registry"
+)
+
+// TestFieldUsers checks which engines accept a custom field and that
+// those engines actually draw from it.
+func TestFieldUsers(t *testing.T) {
+	colors := []core.RGBA{{R: 1, A: 1}, {G: 1, A: 1}}
+	small := map[string]map[string]float64{
+		"contourlines": {"lines": 20, "steps": 50},
+		"flow":         {"dots": 20, "nIters": 20},
+		"flowfield":    {"particles": 20, "steps": 50},
+		"perlinpearls": {"circles": 2, "dots": 10, "nIters": 20},
+		"strata":       {"layers": 2, "depth": 3},
+		"swirl":        {"circles": 3, "dots": 10, "nIters": 20},
+	}
+	field, err := noise.CompileExpr("sin(20*x) * cos(20*y)", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range registry.Engines.Names() {
+		eng, err := registry.Engines.Lookup(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, uses := eng.(core.FieldUser)
+		params, ok := small[name]
+		if uses != ok {
+			t.Errorf("%s: accepts field = %v, want %v", name, uses, ok)
+			continue
+		}
+		if !uses {
+			continue
+		}
+
+		params, err = core.ResolveParams(eng, params)
+		if err != nil {
+			t.Fatal(err)
+		}
+		gen := func(ctx context.Context) core.Scene {
+			scene, err := eng.Generate(ctx, rand.New(rand.NewSource(1)), params, colors)
+			if err != nil {
+				t.Fatalf("%s: %v", name, err)
+			}
+			return scene
+		}
+		if reflect.DeepEqual(gen(context.Background()), gen(noise.WithField(context.Background(), field))) {
+			t.Errorf("%s: custom field has no effect", name)
+		}
+	}
+}
+
+// TestPearlOutline checks that an explicit outlineWidth of 0 draws a
+// zero-width outline, while leaving it unset gives 2×lw.
+func TestPearlOutline(t *testing.T) {
+	eng, err := registry.Engines.Lookup("perlinpearls")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range []struct {
+		params map[string]float64
+		want   float64
+	}{
+		{map[string]float64{}, 0.002},
+		{map[string]float64{"outlineWidth": 0}, 0},
+		{map[string]float64{"outlineWidth": 0.0007}, 0.0007},
+	} {
+		c.params["circles"], c.params["dots"], c.params["nIters"] = 1, 1, 1
+		params, err := core.ResolveParams(eng, c.params)
+		if err != nil {
+			t.Fatal(err)
+		}
+		scene, err := eng.Generate(context.Background(), rand.New(rand.NewSource(1)), params, []core.RGBA{{A: 1}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		outline := scene.Items[len(scene.Items)-1].(core.Stroke)
+		if outline.Width != c.want {
+			t.Errorf("%v: outline width %g, want %g", c.params, outline.Width, c.want)
+		}
+	}
+}
+
+// BenchmarkGrid compares noise-heavy engines sampling their noise
+// exactly and through a pre-sampled grid (param "grid").
+func BenchmarkGrid(b *testing.B) {
+	colors := []core.RGBA{{R: 1, A: 1}, {G: 1, A: 1}, {B: 1, A: 1}}
+	cases := []struct {
+		engine string
+		params map[string]float64
+	}{
+		{"swirl", map[string]float64{"circles": 50, "dots": 100, "nIters": 400}},
+		{"flow", map[string]float64{"dots": 5000, "nIters": 400}},
+	}
+	for _, c := range cases {
+		eng, err := registry.Engines.Lookup(c.engine)
+		if err != nil {
+			b.Fatal(err)
+		}
+		for _, grid := range []float64{0, 256} {
+			params := map[string]float64{"grid": grid}
+			for k, v := range c.params {
+				params[k] = v
+			}
+			params, err := core.ResolveParams(eng, params)
+			if err != nil {
+				b.Fatal(err)
+			}
+			name := c.engine + "/exact"
+			if grid > 0 {
+				name = c.engine + "/grid"
+			}
+			b.Run(name, func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					if _, err := eng.Generate(context.Background(), rand.New(rand.NewSource(1)), params, colors); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+}
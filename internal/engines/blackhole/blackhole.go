@@ -7,28 +7,61 @@ import (
 
 	"genart/internal/core"
 	"genart/internal/noise"
+	"genart/internal/registry"
 )
 
 type Engine struct{}
 
+var spec = append(core.ParamSpec{
+	core.IntParam("circles", 120, 1, math.MaxInt32, "number of concentric rings"),
+	core.FloatParam("density", 0.6, 0, math.Inf(1), "how strongly noise grows toward the outer rings"),
+	core.FloatParam("gap", 0.02, 0, math.Inf(1), "noise-space offset between consecutive rings"),
+	core.FloatParam("lw", 0.0008, 0, 1, "line width as a fraction of min(width,height)"),
+	core.IntParam("segments", 900, 3, math.MaxInt32, "points per ring"),
+	core.FloatParam("hole", 0.1, 0, 0.45, "radius of the empty center"),
+	core.FloatParam("freq", 6.0, 0, math.Inf(1), "noise frequency around each ring"),
+	core.FloatParam("amp", 1.2, 0, math.Inf(1), "radial displacement amplitude"),
+	core.FloatParam("loopRadius", 0.5, 0, math.Inf(1), "noise-space radius walked by a looping animation"),
+}, noise.FractalParams...)
+
 func (Engine) Name() string { return "blackhole" }
 
+func (Engine) Describe() core.ParamSpec { return spec }
+
+func init() { registry.Engines.Register(Engine{}.Name(), Engine{}) }
+
 func (Engine) Generate(_ context.Context, rng *rand.Rand, params map[string]float64, colors []core.RGBA) (core.Scene, error) {
+	return generate(rng, params, colors, func(seed int64) noise.ScalarField3D {
+		return noise.NewSimplexField3D(seed, 1.0)
+	})
+}
+
+// GenerateLoop draws phase t of a seamless loop by walking a circle
+// through 4D simplex noise.
+func (Engine) GenerateLoop(_ context.Context, rng *rand.Rand, params map[string]float64, colors []core.RGBA, t float64) (core.Scene, error) {
+	radius := spec.WithDefaults(params)["loopRadius"]
+	return generate(rng, params, colors, func(seed int64) noise.ScalarField3D {
+		return noise.NewLoop3D(noise.NewSimplexField4D(seed, 1.0), t, radius)
+	})
+}
+
+func generate(rng *rand.Rand, params map[string]float64, colors []core.RGBA, newField func(seed int64) noise.ScalarField3D) (core.Scene, error) {
 	// Parameters
-	circleN := int(core.Pick(params, "circles", 120))
-	density := core.Pick(params, "density", 0.6)
-	circleGap := core.Pick(params, "gap", 0.02)
-	lineWidth := core.Pick(params, "lw", 0.0008)
-	segments := int(core.Pick(params, "segments", 900))
-	hole := core.Pick(params, "hole", 0.1)
-	freq := core.Pick(params, "freq", 6.0)
-	amp := core.Pick(params, "amp", 1.2)
+	params = spec.WithDefaults(params)
+	circleN := int(params["circles"])
+	density := params["density"]
+	circleGap := params["gap"]
+	lineWidth := params["lw"]
+	segments := int(params["segments"])
+	hole := params["hole"]
+	freq := params["freq"]
+	amp := params["amp"]
 
 	centerX, centerY := 0.5, 0.5
 	radiusOuter := 0.45
 
 	// Noise field
-	field := noise.NewSimplexField3D(rng.Int63(), 1.0)
+	field := noise.FractalFor3D(newField(rng.Int63()), params)
 	scene := core.Scene{}
 
 	kMax := 0.5 + rng.Float64()*0.5
@@ -69,7 +102,7 @@ func (Engine) Generate(_ context.Context, rng *rand.Rand, params map[string]floa
 		if len(colors) > 0 {
 			c = colors[rng.Intn(len(colors))]
 		} else {
-			c = core.RGBA{0, 0, 0, 1} // fallback black
+			c = core.RGBA{R: 0, G: 0, B: 0, A: 1} // fallback black
 		}
 
 		// alpha jitter to reduce banding
@@ -7,28 +7,55 @@ import (
 
 	"genart/internal/core"
 	"genart/internal/noise"
+	"genart/internal/registry"
 )
 
 type Engine struct{}
 
+var spec = append(core.ParamSpec{
+	core.IntParam("lines", 3000, 1, math.MaxInt32, "number of dot trails"),
+	core.IntParam("steps", 500, 1, math.MaxInt32, "maximum dots per trail"),
+	core.FloatParam("scale", 0.01, 1e-9, math.Inf(1), "noise zoom (smaller = zoom in)"),
+	core.FloatParam("step", 0.0008, 0, 1, "distance between dots"),
+	core.FloatParam("resetProb", 0.005, 0, 1, "chance per step that a trail ends"),
+	core.FloatParam("dotSize", 0.0015, 0, 1, "dot radius"),
+	core.BoolParam("density", false, "start trails where the field is high, so dots gather in bright areas"),
+}, noise.FieldParams...)
+
 func (Engine) Name() string { return "contourlines" }
 
-func (Engine) Generate(_ context.Context, rng *rand.Rand, params map[string]float64, colors []core.RGBA) (core.Scene, error) {
-	lines := int(pick(params, "lines", 3000))
-	steps := int(pick(params, "steps", 500))
-	scale := pick(params, "scale", 0.01)
-	step := pick(params, "step", 0.0008)
-	resetProb := pick(params, "resetProb", 0.005)
-	dotSize := pick(params, "dotSize", 0.0015)
-	// paletteID := int(pick(params, "palette", 1)) // default warm
+func (Engine) Describe() core.ParamSpec { return spec }
 
-	field := noise.NewSimplexField(rng.Int63(), scale)
-	scene := core.Scene{}
+// AcceptsField marks the engine as using a configured custom field.
+func (Engine) AcceptsField() {}
+
+func init() { registry.Engines.Register(Engine{}.Name(), Engine{}) }
+
+func (Engine) Generate(ctx context.Context, rng *rand.Rand, params map[string]float64, colors []core.RGBA) (core.Scene, error) {
+	params = spec.WithDefaults(params)
+	lines := int(params["lines"])
+	steps := int(params["steps"])
+	scale := params["scale"]
+	step := params["step"]
+	resetProb := params["resetProb"]
+	dotSize := params["dotSize"]
+	density := params["density"] != 0
 
-	// colors := selectPalette(paletteID)
+	var field noise.ScalarField2D = noise.NewSimplexField(rng.Int63(), scale)
+	if custom := noise.FieldFrom(ctx); custom != nil {
+		// noise is sampled at position/100
+		field = noise.Scaled2D{Field: custom, Factor: 100}
+	}
+	field = noise.FieldFor(field, params)
+	scene := core.Scene{}
 
 	for i := 0; i < lines; i++ {
 		x, y := rng.Float64(), rng.Float64()
+		// rejection sampling: keep a start with probability
+		// proportional to the field there, giving up on empty fields
+		for try := 0; density && try < 100 && rng.Float64() > noise.Remap01(field.At(x/100, y/100)); try++ {
+			x, y = rng.Float64(), rng.Float64()
+		}
 		thetaPrev := rng.Float64() * 2 * math.Pi
 
 		for j := 0; j < steps; j++ {
@@ -36,6 +63,10 @@ func (Engine) Generate(_ context.Context, rng *rand.Rand, params map[string]floa
 				break
 			}
 
+			// The heading eases towards the field's angle rather than
+			// following it, so the walk carries state beyond its
+			// position and is not a vector field noise.Integrator can
+			// step; it stays a plain Euler walk.
 			thetaNoise := field.At(x/100, y/100) * math.Pi * 2
 			theta := 0.9*thetaPrev + 0.1*thetaNoise
 			thetaPrev = theta
@@ -73,29 +104,3 @@ func circleAt(cx, cy, r float64, segs int) core.Path {
 	}
 	return core.Path{Points: pts, Closed: true}
 }
-
-func pick(m map[string]float64, k string, def float64) float64 {
-	if v, ok := m[k]; ok {
-		return v
-	}
-	return def
-}
-
-func selectPalette(id int) []core.RGBA {
-	switch id {
-	case 1: // warm
-		return []core.RGBA{
-			{R: 0.9, G: 0.4, B: 0.2, A: 1},
-			{R: 0.95, G: 0.7, B: 0.2, A: 1},
-			{R: 0.7, G: 0.2, B: 0.2, A: 1},
-		}
-	case 2: // cool
-		return []core.RGBA{
-			{R: 0.2, G: 0.5, B: 0.9, A: 1},
-			{R: 0.3, G: 0.8, B: 0.7, A: 1},
-			{R: 0.1, G: 0.2, B: 0.6, A: 1},
-		}
-	default: // mono
-		return []core.RGBA{{R: 0, G: 0, B: 0, A: 1}}
-	}
-}
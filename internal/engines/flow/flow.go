@@ -2,12 +2,17 @@ package flow
 
 import (
 	"context"
+	"fmt"
+	"math"
 	"math/rand"
+	"slices"
 
 	"genart/internal/colorize"
 	"genart/internal/core"
 	"genart/internal/noise"
+	"genart/internal/palette"
 	"genart/internal/randutil"
+	"genart/internal/registry"
 )
 
 type Engine struct{}
@@ -15,17 +20,65 @@ type Engine struct{}
 type dot struct {
 	x, y         float64
 	prevx, prevy float64
+	h            float64 // step size, varies with an adaptive integrator
 }
 
+var spec = slices.Concat(core.ParamSpec{
+	core.IntParam("dots", 5000, 1, math.MaxInt32, "number of particles"),
+	core.FloatParam("lw", 0.001, 0, 1, "line width as a fraction of min(width,height)"),
+	core.IntParam("nIters", 100, 1, math.MaxInt32, "steps per particle"),
+	core.FloatParam("factor", 1.5, 0, math.Inf(1), "noise frequency"),
+	core.FloatParam("step", 0.005, 0, 1, "distance moved per step"),
+	core.FloatParam("loopRadius", 0.5, 0, math.Inf(1), "noise-space radius walked by a looping animation"),
+	core.BoolParam("tile", false, "periodic noise and exact wrapping, so the output repeats seamlessly"),
+}, colorize.NoiseParams, noise.PerlinParams, noise.FieldParams, noise.IntegratorParams, noise.GridParams)
+
 func (Engine) Name() string { return "flow" }
 
+func (Engine) Describe() core.ParamSpec { return spec }
+
+// AcceptsField marks the engine as using a configured custom field.
+func (Engine) AcceptsField() {}
+
+func init() { registry.Engines.Register(Engine{}.Name(), Engine{}) }
+
 func (Engine) Generate(ctx context.Context, rng *rand.Rand, params map[string]float64, colors []core.RGBA) (core.Scene, error) {
+	return generate(ctx, rng, params, colors, func(seed int64) noise.ScalarField2D {
+		return noise.PerlinFor(seed, 1.0, spec.WithDefaults(params))
+	})
+}
+
+// GenerateLoop draws phase t of a seamless loop. go-perlin has no 4D
+// noise, so looping runs switch to a 2D slice of 4D simplex noise.
+func (Engine) GenerateLoop(ctx context.Context, rng *rand.Rand, params map[string]float64, colors []core.RGBA, t float64) (core.Scene, error) {
+	params = spec.WithDefaults(params)
+	if params["tile"] != 0 {
+		return core.Scene{}, fmt.Errorf("tile is not supported in loop mode")
+	}
+	radius := params["loopRadius"]
+	return generate(ctx, rng, params, colors, func(seed int64) noise.ScalarField2D {
+		return noise.NewLoop2D(noise.NewSimplexField4D(seed, 1.0), t, radius)
+	})
+}
+
+func generate(ctx context.Context, rng *rand.Rand, params map[string]float64, colors []core.RGBA, newField func(seed int64) noise.ScalarField2D) (core.Scene, error) {
 	// Parameters
-	dotsN := int(core.Pick(params, "dots", 5000))
-	lineWidth := core.Pick(params, "lw", 0.001)
-	nIters := int(core.Pick(params, "nIters", 100))
-	factor := core.Pick(params, "factor", 1.5)
-	step := core.Pick(params, "step", 0.005)
+	params = spec.WithDefaults(params)
+	dotsN := int(params["dots"])
+	lineWidth := params["lw"]
+	nIters := int(params["nIters"])
+	factor := params["factor"]
+	step := params["step"]
+	tile := params["tile"] != 0
+	custom := noise.FieldFrom(ctx)
+	if tile && custom != nil {
+		return core.Scene{}, fmt.Errorf("tile is not supported with a custom field")
+	}
+	if tile {
+		if err := noise.CheckTileable(params); err != nil {
+			return core.Scene{}, err
+		}
+	}
 
 	scene := core.Scene{}
 
@@ -39,23 +92,38 @@ func (Engine) Generate(ctx context.Context, rng *rand.Rand, params map[string]fl
 			y:     y,
 			prevx: x,
 			prevy: y,
+			h:     step,
 		})
 	}
 
-	noiseField := noise.NewPerlinField(rng.Int63(), 1.0)
+	var noiseField noise.ScalarField2D
+	if tile {
+		// noise is sampled at position*factor, so it repeats every factor
+		noiseField = noise.NewTile2D(noise.NewSimplexField4D(rng.Int63(), 1.0), factor, 1.0)
+	} else {
+		noiseField = newField(rng.Int63())
+	}
+	if custom != nil {
+		// the canvas spans [0,factor] in noise space
+		noiseField = noise.Scaled2D{Field: custom, Factor: 1 / factor}
+	}
+	noiseField = noise.FieldFor(noiseField, params)
 	const epsilon = 0.001
+	// noise is sampled at position*factor, with positions in [0,1]
+	// give or take a step
+	noiseField, curlField := noise.CachedFor(noiseField, epsilon, -0.05*factor, 1.05*factor, params)
+	pick := colorize.NoiseColorerFor(colors, palette.RampFrom(ctx), noiseField, factor, params)
+	curl := noise.ScaledField{Field: curlField, Factor: factor}
+	in := noise.IntegratorFor(params, step)
 
 	for i := 0; i < nIters; i++ {
 		for k := range ds {
-			// curl noise
-			nx, ny := noise.Curl2D(noiseField, ds[k].x*factor, ds[k].y*factor, epsilon)
-
+			// follow curl noise
 			ds[k].prevx, ds[k].prevy = ds[k].x, ds[k].y
-			ds[k].x += nx * step
-			ds[k].y += ny * step
+			ds[k].x, ds[k].y, ds[k].h = in.Advance(curl, ds[k].x, ds[k].y, ds[k].h)
 
 			// pick color based on noise value at current position
-			c := colorize.PickColorFromNoise(colors, noiseField, ds[k].x, ds[k].y, factor)
+			c := pick(ds[k].x, ds[k].y)
 
 			alpha := 0.05 + rng.Float64()*0.1
 			lw := lineWidth * randutil.RandomRangeFloat64(rng, 0.8, 1.2)
@@ -65,7 +133,11 @@ func (Engine) Generate(ctx context.Context, rng *rand.Rand, params map[string]fl
 			}
 			scene.AddStroke(points, false, lw, c, alpha)
 
-			// wrap around
+			// wrap around; tiles wrap exactly so paths continue seamlessly
+			if tile && (ds[k].x < 0 || ds[k].x > 1 || ds[k].y < 0 || ds[k].y > 1) {
+				ds[k].x, ds[k].y = noise.Wrap(ds[k].x), noise.Wrap(ds[k].y)
+				ds[k].prevx, ds[k].prevy = ds[k].x, ds[k].y
+			}
 			if ds[k].x < 0 {
 				ds[k].x = 1
 				ds[k].prevx = ds[k].x
@@ -5,23 +5,52 @@ import (
 	"fmt"
 	"math"
 	"math/rand"
+	"slices"
 
 	"genart/internal/core"
 	"genart/internal/noise"
+	"genart/internal/registry"
 )
 
 type Engine struct{}
 
+var spec = append(core.ParamSpec{
+	core.IntParam("particles", 1000, 1, math.MaxInt32, "number of streamlines"),
+	core.IntParam("steps", 300, 1, math.MaxInt32, "maximum points per streamline"),
+	core.FloatParam("scale", 0.002, 1e-9, math.Inf(1), "noise zoom (smaller = zoom in)"),
+	core.FloatParam("step", 0.002, 1e-9, 1, "distance moved per step"),
+	core.FloatParam("lw", 0.0015, 0, 1, "line width as a fraction of min(width,height)"),
+	core.BoolParam("tile", false, "periodic noise and streamlines wrapping at the edges, so the output repeats seamlessly"),
+}, slices.Concat(noise.FieldParams, noise.IntegratorParams)...)
+
 func (Engine) Name() string { return "flowfield" }
 
-func (Engine) Generate(_ context.Context, rng *rand.Rand, params map[string]float64, colors []core.RGBA) (core.Scene, error) {
-	// --- Params with defaults ---
-	particles := int(pick(params, "particles", 1000))
-	steps := int(pick(params, "steps", 300))
-	scale := pick(params, "scale", 0.002)
-	step := pick(params, "step", 0.002)
-	lw := pick(params, "lw", 0.0015)
+func (Engine) Describe() core.ParamSpec { return spec }
+
+// AcceptsField marks the engine as using a configured custom field.
+func (Engine) AcceptsField() {}
 
+func init() { registry.Engines.Register(Engine{}.Name(), Engine{}) }
+
+func (Engine) Generate(ctx context.Context, rng *rand.Rand, params map[string]float64, colors []core.RGBA) (core.Scene, error) {
+	// --- Params with defaults ---
+	params = spec.WithDefaults(params)
+	particles := int(params["particles"])
+	steps := int(params["steps"])
+	scale := params["scale"]
+	step := params["step"]
+	lw := params["lw"]
+	tile := params["tile"] != 0
+	custom := noise.FieldFrom(ctx)
+
+	if tile && custom != nil {
+		return core.Scene{}, fmt.Errorf("tile is not supported with a custom field")
+	}
+	if tile {
+		if err := noise.CheckTileable(params); err != nil {
+			return core.Scene{}, err
+		}
+	}
 	if particles <= 0 {
 		return core.Scene{}, fmt.Errorf("invalid particles %d (must be > 0)", particles)
 	}
@@ -39,48 +68,58 @@ func (Engine) Generate(_ context.Context, rng *rand.Rand, params map[string]floa
 	}
 
 	// --- Field: deterministic with sub-seed ---
-	field := noise.NewSimplexField(rng.Int63(), scale)
+	var field noise.ScalarField2D
+	if tile {
+		field = noise.NewTile2D(noise.NewSimplexField4D(rng.Int63(), 1.0), 1, scale)
+	} else {
+		field = noise.NewSimplexField(rng.Int63(), scale)
+	}
+	if custom != nil {
+		field = custom
+	}
+	field = noise.FieldFor(field, params)
+	heading := noise.AngleField{Field: field, Turns: 1} // [-1,1] → [-π,π]
+	in := noise.IntegratorFor(params, step)
 
 	scene := core.Scene{}
 
 	for i := 0; i < particles; i++ {
 		// random start in [0,1]
 		x, y := rng.Float64(), rng.Float64()
+		h := step
 		points := make([]core.Vec2, 0, steps)
 
 		for j := 0; j < steps; j++ {
-			// field → angle in radians
-			angle := field.At(x, y) * math.Pi // [-1,1] → [-π,π]
-			dx := math.Cos(angle) * step
-			dy := math.Sin(angle) * step
-
-			x += dx
-			y += dy
+			x, y, h = in.Advance(heading, x, y, h)
 
-			// stop if out of bounds
+			// stop if out of bounds; tiles instead end the piece just
+			// past the edge and continue from the opposite side
 			if x < 0 || x > 1 || y < 0 || y > 1 {
-				break
+				if !tile {
+					break
+				}
+				points = append(points, core.Vec2{X: x, Y: y})
+				addStreamline(&scene, points, lw)
+				x, y = noise.Wrap(x), noise.Wrap(y)
+				points = make([]core.Vec2, 0, steps-j)
 			}
 
 			points = append(points, core.Vec2{X: x, Y: y})
 		}
 
-		if len(points) > 1 {
-			scene.Items = append(scene.Items, core.Stroke{
-				Path:  core.Path{Points: points, Closed: false},
-				Width: lw,
-				Color: core.RGBA{R: 0, G: 0, B: 0, A: 0.3}, // translucent black
-				Alpha: 0.3,
-			})
-		}
+		addStreamline(&scene, points, lw)
 	}
 
 	return scene, nil
 }
 
-func pick(m map[string]float64, k string, def float64) float64 {
-	if v, ok := m[k]; ok {
-		return v
+func addStreamline(scene *core.Scene, points []core.Vec2, lw float64) {
+	if len(points) > 1 {
+		scene.Items = append(scene.Items, core.Stroke{
+			Path:  core.Path{Points: points, Closed: false},
+			Width: lw,
+			Color: core.RGBA{R: 0, G: 0, B: 0, A: 0.3}, // translucent black
+			Alpha: 0.3,
+		})
 	}
-	return def
 }
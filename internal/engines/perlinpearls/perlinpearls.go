@@ -4,12 +4,15 @@ import (
 	"context"
 	"math"
 	"math/rand"
+	"slices"
 
 	"genart/internal/colorize"
 	"genart/internal/core"
 	"genart/internal/geom"
 	"genart/internal/noise"
+	"genart/internal/palette"
 	"genart/internal/randutil"
+	"genart/internal/registry"
 )
 
 type Engine struct{}
@@ -27,19 +30,40 @@ type dot struct {
 	step         float64
 }
 
+var spec = slices.Concat(core.ParamSpec{
+	core.IntParam("circles", 5, 1, math.MaxInt32, "number of non-overlapping pearls"),
+	core.IntParam("dots", 500, 1, math.MaxInt32, "particles per pearl"),
+	core.FloatParam("lw", 0.001, 0, 1, "line width as a fraction of min(width,height)"),
+	core.IntParam("nIters", 2000, 1, math.MaxInt32, "steps per particle"),
+	core.FloatParam("factor", 1.5, 0, math.Inf(1), "noise frequency"),
+	core.FloatParam("step", 0.003, 0, 1, "distance moved per step"),
+	core.FloatParam("outlineWidth", -1, -1, 1, "pearl outline width (-1 = 2×lw)"),
+}, colorize.NoiseParams, noise.PerlinParams, noise.FieldParams, noise.IntegratorParams, noise.GridParams)
+
 func (Engine) Name() string { return "perlinpearls" }
 
+func (Engine) Describe() core.ParamSpec { return spec }
+
+// AcceptsField marks the engine as using a configured custom field.
+func (Engine) AcceptsField() {}
+
+func init() { registry.Engines.Register(Engine{}.Name(), Engine{}, "pearls") }
+
 func (Engine) Generate(ctx context.Context, rng *rand.Rand, params map[string]float64, colors []core.RGBA) (core.Scene, error) {
 	// Parameters
-	circleN := int(core.Pick(params, "circles", 5))
-	dotsN := int(core.Pick(params, "dots", 500))
-	lineWidth := core.Pick(params, "lw", 0.001)
-	nIters := int(core.Pick(params, "nIters", 2000))
-	factor := core.Pick(params, "factor", 1.5)
-	step := core.Pick(params, "step", 0.003)
+	params = spec.WithDefaults(params)
+	circleN := int(params["circles"])
+	dotsN := int(params["dots"])
+	lineWidth := params["lw"]
+	nIters := int(params["nIters"])
+	factor := params["factor"]
+	step := params["step"]
 
 	// outline params
-	outlineWidth := core.Pick(params, "outlineWidth", lineWidth*2)
+	outlineWidth := params["outlineWidth"]
+	if outlineWidth < 0 {
+		outlineWidth = lineWidth * 2
+	}
 	scene := core.Scene{}
 
 	// generate non-overlapping circles
@@ -84,22 +108,31 @@ func (Engine) Generate(ctx context.Context, rng *rand.Rand, params map[string]fl
 		ds = append(ds, dots)
 	}
 
-	noiseField := noise.NewPerlinField(rng.Int63(), 1.0)
+	var noiseField noise.ScalarField2D = noise.PerlinFor(rng.Int63(), 1.0, params)
+	if custom := noise.FieldFrom(ctx); custom != nil {
+		// the canvas spans [0,factor] in noise space
+		noiseField = noise.Scaled2D{Field: custom, Factor: 1 / factor}
+	}
+	noiseField = noise.FieldFor(noiseField, params)
 	const epsilon = 0.001
+	// noise is sampled at position*factor, with positions in [0,1]
+	// give or take a step
+	noiseField, curlField := noise.CachedFor(noiseField, epsilon, -0.05*factor, 1.05*factor, params)
+	pick := colorize.NoiseColorerFor(colors, palette.RampFrom(ctx), noiseField, factor, params)
+	curl := noise.ScaledField{Field: curlField, Factor: factor}
+	in := noise.IntegratorFor(params, step)
 
 	for i := 0; i < circleN; i++ {
 		for j := 0; j < nIters; j++ {
 			for k := range ds[i] {
-				// curl noise
-				nx, ny := noise.Curl2D(noiseField, ds[i][k].x*factor, ds[i][k].y*factor, epsilon)
-
-				ds[i][k].prevx, ds[i][k].prevy = ds[i][k].x, ds[i][k].y
-				ds[i][k].x += nx * ds[i][k].step
-				ds[i][k].y += ny * ds[i][k].step
+				// follow curl noise
+				d := &ds[i][k]
+				d.prevx, d.prevy = d.x, d.y
+				d.x, d.y, d.step = in.Advance(curl, d.x, d.y, d.step)
 
 				// inside the stroke drawing loop
 				// pick color based on noise value at current position
-				c := colorize.PickColorFromNoise(colors, noiseField, ds[i][k].x, ds[i][k].y, factor)
+				c := pick(ds[i][k].x, ds[i][k].y)
 
 				// only draw if inside circle
 				if (geom.Vec2{X: ds[i][k].x, Y: ds[i][k].y}).Distance(geom.Vec2{X: cs[i].x, Y: cs[i].y}) < cs[i].radius &&
@@ -4,26 +4,48 @@ import (
 	"context"
 	"math"
 	"math/rand"
+	"slices"
 
 	"genart/internal/core"
 	"genart/internal/geom"
 	"genart/internal/noise"
+	"genart/internal/registry"
 )
 
 type Engine struct{}
 
+var spec = append(core.ParamSpec{
+	core.IntParam("sides", 6, 3, math.MaxInt32, "sides of the base polygon"),
+	core.IntParam("layers", 20, 1, math.MaxInt32, "number of stacked polygons"),
+	core.IntParam("depth", 5, 0, 12, "subdivision passes"),
+	core.FloatParam("magnitude", 0.1, 0, math.Inf(1), "noise displacement of midpoints"),
+	core.FloatParam("rotation", 0.01, math.Inf(-1), math.Inf(1), "rotation per layer in radians"),
+}, slices.Concat(noise.PerlinParams, noise.FieldParams)...)
+
 func (Engine) Name() string { return "strata" }
 
+func (Engine) Describe() core.ParamSpec { return spec }
+
+// AcceptsField marks the engine as using a configured custom field.
+func (Engine) AcceptsField() {}
+
+func init() { registry.Engines.Register(Engine{}.Name(), Engine{}) }
+
 func (Engine) Generate(ctx context.Context, rng *rand.Rand, params map[string]float64, colors []core.RGBA) (core.Scene, error) {
 	// Parameters
-	sides := int(core.Pick(params, "sides", 6))
-	layers := int(core.Pick(params, "layers", 20))
-	depth := int(core.Pick(params, "depth", 5))
-	magnitude := core.Pick(params, "magnitude", 0.1)
-	rotation := core.Pick(params, "rotation", 0.01)
+	params = spec.WithDefaults(params)
+	sides := int(params["sides"])
+	layers := int(params["layers"])
+	depth := int(params["depth"])
+	magnitude := params["magnitude"]
+	rotation := params["rotation"]
 
 	scene := core.Scene{}
-	noiseField := noise.NewPerlinField(rng.Int63(), 1.0)
+	var noiseField noise.ScalarField2D = noise.PerlinFor(rng.Int63(), 1.0, params)
+	if custom := noise.FieldFrom(ctx); custom != nil {
+		noiseField = custom
+	}
+	noiseField = noise.FieldFor(noiseField, params)
 
 	for i := 0; i < layers; i++ {
 		// Base polygon
@@ -0,0 +1,68 @@
+package noise
+
+import (
+	"context"
+	"fmt"
+
+	"genart/internal/config"
+)
+
+// A custom field replaces the seeded noise of engines that accept one.
+// It is sampled in canvas coordinates, [0,1] across the output, and
+// reaches engines through the context passed to Generate.
+
+type fieldKey struct{}
+
+// WithField returns a context carrying f as the custom field.
+func WithField(ctx context.Context, f ScalarField2D) context.Context {
+	return context.WithValue(ctx, fieldKey{}, f)
+}
+
+// FieldFrom returns the custom field carried by ctx, or nil.
+func FieldFrom(ctx context.Context) ScalarField2D {
+	f, _ := ctx.Value(fieldKey{}).(ScalarField2D)
+	return f
+}
+
+// Resolve builds the custom field configured by fc for a canvas of the
+// given aspect ratio (width/height); seed feeds the noise sources of a
+// formula. It returns nil when fc is nil.
+func Resolve(fc *config.FieldConfig, seed int64, aspect float64) (ScalarField2D, error) {
+	switch {
+	case fc == nil:
+		return nil, nil
+	case fc.Expr != "" && fc.Image != "":
+		return nil, fmt.Errorf("set either expr or image, not both")
+	case fc.Expr != "":
+		f, err := CompileExpr(fc.Expr, seed)
+		if err != nil {
+			return nil, err
+		}
+		return f, nil
+	case fc.Image == "":
+		return nil, fmt.Errorf("missing expr or image")
+	}
+
+	f, err := LoadImageField(fc.Image, ImageOptions{
+		Channel: Channel(fc.Channel),
+		Fit:     Fit(fc.Fit),
+		Aspect:  aspect,
+		Blur:    fc.Blur,
+		Invert:  fc.Invert,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Scaled2D samples Field at (x,y)·Factor; engines use it to map their
+// own noise coordinates back onto the canvas.
+type Scaled2D struct {
+	Field  ScalarField2D
+	Factor float64
+}
+
+func (s Scaled2D) At(x, y float64) float64 {
+	return s.Field.At(x*s.Factor, y*s.Factor)
+}
@@ -0,0 +1,461 @@
+package noise
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ExprField is a ScalarField2D defined by a formula over x and y, such
+// as "sin(10*x) + 0.5*simplex(x*4, y*4)". The formula is parsed once
+// into a tree of closures, so At costs no more than the arithmetic. It
+// can only compute: there are no assignments, loops or I/O.
+//
+// Syntax: numbers, x, y, pi and tau; + - * / % and ^ (power, right
+// associative, binding tighter than unary minus); parentheses; and calls:
+//
+//	sin cos tan asin acos atan sqrt exp log abs floor ceil fract sign
+//	atan2(y,x) pow(a,b) mod(a,b) step(edge,v) min(a,b,...) max(a,b,...)
+//	clamp(v,lo,hi) mix(a,b,t) smoothstep(e0,e1,v)
+//	simplex(x,y) perlin(x,y) value(x,y) worley(x,y)
+//	fbm(x,y[,octaves]) ridged(x,y[,octaves])
+//
+// The noise sources are at scale 1, seeded from the seed given to
+// CompileExpr; fbm and ridged layer simplex noise, 4 octaves unless
+// told otherwise, up to 16.
+type ExprField struct {
+	src  string
+	eval exprFn
+}
+
+type exprFn func(x, y float64) float64
+
+// maxExprDepth bounds nesting, so a hostile formula cannot exhaust the
+// stack while parsing.
+const maxExprDepth = 64
+
+// CompileExpr parses src into an ExprField.
+func CompileExpr(src string, seed int64) (*ExprField, error) {
+	toks, err := lexExpr(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &exprParser{toks: toks, seed: seed, sources: map[string]ScalarField2D{}}
+	eval, err := p.expr()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokEOF {
+		return nil, p.errorf(t, "unexpected %q", t.text)
+	}
+	return &ExprField{src: src, eval: eval}, nil
+}
+
+// At evaluates the formula at (x,y). Results that are not finite, such
+// as a division by zero, read as 0.
+func (e *ExprField) At(x, y float64) float64 {
+	v := e.eval(x, y)
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return 0
+	}
+	return v
+}
+
+// String returns the source formula.
+func (e *ExprField) String() string { return e.src }
+
+// exprFuncs are the plain functions an expression can call, by arity.
+var exprFuncs = struct {
+	unary   map[string]func(float64) float64
+	binary  map[string]func(float64, float64) float64
+	ternary map[string]func(float64, float64, float64) float64
+}{
+	unary: map[string]func(float64) float64{
+		"sin": math.Sin, "cos": math.Cos, "tan": math.Tan,
+		"asin": math.Asin, "acos": math.Acos, "atan": math.Atan,
+		"sqrt": math.Sqrt, "exp": math.Exp, "log": math.Log,
+		"abs": math.Abs, "floor": math.Floor, "ceil": math.Ceil,
+		"fract": func(v float64) float64 { return v - math.Floor(v) },
+		"sign": func(v float64) float64 {
+			if v == 0 {
+				return 0
+			}
+			return math.Copysign(1, v)
+		},
+	},
+	binary: map[string]func(float64, float64) float64{
+		"atan2": math.Atan2,
+		"pow":   math.Pow,
+		"min":   math.Min,
+		"max":   math.Max,
+		"mod":   func(a, b float64) float64 { return a - b*math.Floor(a/b) },
+		"step": func(edge, v float64) float64 {
+			if v < edge {
+				return 0
+			}
+			return 1
+		},
+	},
+	ternary: map[string]func(float64, float64, float64) float64{
+		"clamp": func(v, lo, hi float64) float64 { return math.Max(lo, math.Min(hi, v)) },
+		"mix":   lerp,
+		"smoothstep": func(e0, e1, v float64) float64 {
+			t := math.Max(0, math.Min(1, (v-e0)/(e1-e0)))
+			return t * t * (3 - 2*t)
+		},
+	},
+}
+
+// exprSources builds the noise sources an expression can call.
+var exprSources = map[string]func(seed int64) ScalarField2D{
+	"simplex": func(seed int64) ScalarField2D { return NewSimplexField(seed, 1) },
+	"perlin":  func(seed int64) ScalarField2D { return NewPerlin2D(seed, 1, 1, 0.5) },
+	"value":   func(seed int64) ScalarField2D { return NewValueField(seed, 1) },
+	"worley":  func(seed int64) ScalarField2D { return NewWorleyField(seed, 1, F1, Euclidean) },
+}
+
+// exprFractals are the layered sources, over simplex noise.
+var exprFractals = map[string]FractalKind{"fbm": FBM, "ridged": Ridged}
+
+type tokKind int
+
+const (
+	tokEOF tokKind = iota
+	tokNum
+	tokIdent
+	tokOp
+)
+
+type token struct {
+	kind tokKind
+	text string
+	num  float64
+	pos  int // byte offset in the source
+}
+
+func lexExpr(src string) ([]token, error) {
+	var toks []token
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c >= '0' && c <= '9' || c == '.':
+			j := i
+			for j < len(src) && (src[j] >= '0' && src[j] <= '9' || src[j] == '.') {
+				j++
+			}
+			// exponent, as in 1e-3
+			if j < len(src) && (src[j] == 'e' || src[j] == 'E') {
+				k := j + 1
+				if k < len(src) && (src[k] == '+' || src[k] == '-') {
+					k++
+				}
+				if k < len(src) && src[k] >= '0' && src[k] <= '9' {
+					for j = k; j < len(src) && src[j] >= '0' && src[j] <= '9'; j++ {
+					}
+				}
+			}
+			v, err := strconv.ParseFloat(src[i:j], 64)
+			if err != nil {
+				return nil, fmt.Errorf("expr: bad number %q at %d", src[i:j], i)
+			}
+			toks = append(toks, token{kind: tokNum, text: src[i:j], num: v, pos: i})
+			i = j
+		case c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z':
+			j := i
+			for j < len(src) && (src[j] == '_' || src[j] >= 'a' && src[j] <= 'z' || src[j] >= 'A' && src[j] <= 'Z' || src[j] >= '0' && src[j] <= '9') {
+				j++
+			}
+			toks = append(toks, token{kind: tokIdent, text: src[i:j], pos: i})
+			i = j
+		case strings.IndexByte("+-*/%^(),", c) >= 0:
+			toks = append(toks, token{kind: tokOp, text: src[i : i+1], pos: i})
+			i++
+		default:
+			return nil, fmt.Errorf("expr: unexpected %q at %d", c, i)
+		}
+	}
+	return append(toks, token{kind: tokEOF, text: "end of input", pos: len(src)}), nil
+}
+
+// exprParser is a recursive descent parser, one method per precedence
+// level, lowest first.
+type exprParser struct {
+	toks    []token
+	pos     int
+	depth   int
+	seed    int64
+	sources map[string]ScalarField2D // built on first use
+}
+
+func (p *exprParser) peek() token { return p.toks[p.pos] }
+
+func (p *exprParser) next() token {
+	t := p.toks[p.pos]
+	if t.kind != tokEOF {
+		p.pos++
+	}
+	return t
+}
+
+// accept consumes the next token if it is the operator op.
+func (p *exprParser) accept(op string) bool {
+	if t := p.peek(); t.kind == tokOp && t.text == op {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *exprParser) expect(op string) error {
+	if !p.accept(op) {
+		t := p.peek()
+		return p.errorf(t, "expected %q, found %q", op, t.text)
+	}
+	return nil
+}
+
+func (p *exprParser) errorf(t token, format string, args ...any) error {
+	return fmt.Errorf("expr: %s at %d", fmt.Sprintf(format, args...), t.pos)
+}
+
+// expr := term (("+" | "-") term)*
+func (p *exprParser) expr() (exprFn, error) {
+	l, err := p.term()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		switch {
+		case p.accept("+"):
+			r, err := p.term()
+			if err != nil {
+				return nil, err
+			}
+			l = add(l, r)
+		case p.accept("-"):
+			r, err := p.term()
+			if err != nil {
+				return nil, err
+			}
+			l = sub(l, r)
+		default:
+			return l, nil
+		}
+	}
+}
+
+// term := unary (("*" | "/" | "%") unary)*
+func (p *exprParser) term() (exprFn, error) {
+	l, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		var op func(a, b float64) float64
+		switch {
+		case p.accept("*"):
+			op = func(a, b float64) float64 { return a * b }
+		case p.accept("/"):
+			op = func(a, b float64) float64 { return a / b }
+		case p.accept("%"):
+			op = exprFuncs.binary["mod"]
+		default:
+			return l, nil
+		}
+		r, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		l = apply2(op, l, r)
+	}
+}
+
+// unary := ("-" | "+") unary | power
+func (p *exprParser) unary() (exprFn, error) {
+	// every level of nesting passes through here
+	p.depth++
+	defer func() { p.depth-- }()
+	if p.depth > maxExprDepth {
+		return nil, p.errorf(p.peek(), "nested too deeply")
+	}
+
+	if p.accept("-") {
+		v, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return func(x, y float64) float64 { return -v(x, y) }, nil
+	}
+	if p.accept("+") {
+		return p.unary()
+	}
+	return p.power()
+}
+
+// power := primary ("^" unary)?
+func (p *exprParser) power() (exprFn, error) {
+	base, err := p.primary()
+	if err != nil {
+		return nil, err
+	}
+	if !p.accept("^") {
+		return base, nil
+	}
+	exp, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	return apply2(math.Pow, base, exp), nil
+}
+
+// primary := number | name | name "(" args ")" | "(" expr ")"
+func (p *exprParser) primary() (exprFn, error) {
+	t := p.next()
+	switch t.kind {
+	case tokNum:
+		return constant(t.num), nil
+	case tokIdent:
+		if p.accept("(") {
+			return p.call(t)
+		}
+		switch t.text {
+		case "x":
+			return func(x, _ float64) float64 { return x }, nil
+		case "y":
+			return func(_, y float64) float64 { return y }, nil
+		case "pi":
+			return constant(math.Pi), nil
+		case "tau":
+			return constant(2 * math.Pi), nil
+		}
+		return nil, p.errorf(t, "unknown name %q (available: x, y, pi, tau)", t.text)
+	case tokOp:
+		if t.text == "(" {
+			v, err := p.expr()
+			if err != nil {
+				return nil, err
+			}
+			return v, p.expect(")")
+		}
+	}
+	return nil, p.errorf(t, "unexpected %q", t.text)
+}
+
+// call parses the arguments of fn, whose "(" is consumed, and binds it.
+func (p *exprParser) call(fn token) (exprFn, error) {
+	var args []exprFn
+	if !p.accept(")") {
+		for {
+			a, err := p.expr()
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, a)
+			if p.accept(")") {
+				break
+			}
+			if !p.accept(",") {
+				t := p.peek()
+				return nil, p.errorf(t, "expected \",\" or \")\" in call to %s, found %q", fn.text, t.text)
+			}
+		}
+	}
+
+	arity := func(lo, hi int) error {
+		if len(args) < lo || len(args) > hi {
+			want := strconv.Itoa(lo)
+			if hi > lo {
+				want = fmt.Sprintf("%d to %d", lo, hi)
+			}
+			return p.errorf(fn, "%s takes %s arguments, got %d", fn.text, want, len(args))
+		}
+		return nil
+	}
+	name := fn.text
+
+	if f, ok := exprFuncs.unary[name]; ok {
+		if err := arity(1, 1); err != nil {
+			return nil, err
+		}
+		a := args[0]
+		return func(x, y float64) float64 { return f(a(x, y)) }, nil
+	}
+	if f, ok := exprFuncs.binary[name]; ok {
+		// min and max fold over any number of arguments
+		hi := 2
+		if name == "min" || name == "max" {
+			hi = math.MaxInt
+		}
+		if err := arity(2, hi); err != nil {
+			return nil, err
+		}
+		v := args[0]
+		for _, a := range args[1:] {
+			v = apply2(f, v, a)
+		}
+		return v, nil
+	}
+	if f, ok := exprFuncs.ternary[name]; ok {
+		if err := arity(3, 3); err != nil {
+			return nil, err
+		}
+		a, b, c := args[0], args[1], args[2]
+		return func(x, y float64) float64 { return f(a(x, y), b(x, y), c(x, y)) }, nil
+	}
+	if _, ok := exprSources[name]; ok {
+		if err := arity(2, 2); err != nil {
+			return nil, err
+		}
+		src := p.source(name)
+		a, b := args[0], args[1]
+		return func(x, y float64) float64 { return src.At(a(x, y), b(x, y)) }, nil
+	}
+	if kind, ok := exprFractals[name]; ok {
+		if err := arity(2, 3); err != nil {
+			return nil, err
+		}
+		n := constant(float64(DefaultOctaves.N))
+		if len(args) == 3 {
+			n = args[2]
+		}
+		src := p.source("simplex")
+		a, b := args[0], args[1]
+		return func(x, y float64) float64 {
+			oct := DefaultOctaves
+			oct.N = int(math.Max(1, math.Min(16, n(x, y))))
+			u, v := a(x, y), b(x, y)
+			return sumOctaves(kind, oct, func(freq, shift float64) float64 {
+				return src.At(u*freq+shift, v*freq+shift)
+			})
+		}, nil
+	}
+	return nil, p.errorf(fn, "unknown function %q", name)
+}
+
+// source returns the noise source called name, building it on first
+// use. Each name has its own seed, so simplex and perlin do not line up.
+func (p *exprParser) source(name string) ScalarField2D {
+	if f, ok := p.sources[name]; ok {
+		return f
+	}
+	var h uint64
+	for _, c := range []byte(name) {
+		h = h*31 + uint64(c)
+	}
+	f := exprSources[name](int64(hash(p.seed, int(h&0x7fffffff))))
+	p.sources[name] = f
+	return f
+}
+
+func constant(v float64) exprFn { return func(_, _ float64) float64 { return v } }
+
+func add(a, b exprFn) exprFn { return func(x, y float64) float64 { return a(x, y) + b(x, y) } }
+
+func sub(a, b exprFn) exprFn { return func(x, y float64) float64 { return a(x, y) - b(x, y) } }
+
+func apply2(f func(a, b float64) float64, a, b exprFn) exprFn {
+	return func(x, y float64) float64 { return f(a(x, y), b(x, y)) }
+}
@@ -0,0 +1,143 @@
+package noise
+
+import (
+	"math"
+
+	"genart/internal/core"
+)
+
+// FractalKind selects how octaves of a field are layered.
+type FractalKind string
+
+const (
+	FBM        FractalKind = "fbm"        // plain sum: fractal Brownian motion
+	Ridged     FractalKind = "ridged"     // inverted |n|, each octave weighted by the last: sharp crests
+	Turbulence FractalKind = "turbulence" // sum of |n|, in [0,1]: creases at the zero crossings
+	Billow     FractalKind = "billow"     // turbulence stretched back to [-1,1]: puffy lumps
+)
+
+// Octaves controls a fractal sum. Each octave samples the field at
+// Lacunarity times the previous frequency with Gain times its amplitude.
+type Octaves struct {
+	N          int
+	Lacunarity float64
+	Gain       float64
+}
+
+// DefaultOctaves is a common starting point: 4 octaves, each at double
+// the frequency and half the amplitude of the previous one.
+var DefaultOctaves = Octaves{N: 4, Lacunarity: 2, Gain: 0.5}
+
+// octaveShift offsets each octave's samples so octaves do not all line
+// up at the origin.
+const octaveShift = 19.19
+
+// Fractal2D layers octaves of a ScalarField2D. At stays in [-1,1].
+type Fractal2D struct {
+	field ScalarField2D
+	kind  FractalKind
+	oct   Octaves
+}
+
+// NewFractal2D wraps field in a fractal sum of the given kind.
+func NewFractal2D(field ScalarField2D, kind FractalKind, oct Octaves) *Fractal2D {
+	return &Fractal2D{field: field, kind: kind, oct: oct}
+}
+
+// At returns the fractal value at (x,y), in [-1,1].
+func (f *Fractal2D) At(x, y float64) float64 {
+	return sumOctaves(f.kind, f.oct, func(freq, shift float64) float64 {
+		return f.field.At(x*freq+shift, y*freq+shift)
+	})
+}
+
+// Fractal3D layers octaves of a ScalarField3D. At stays in [-1,1].
+type Fractal3D struct {
+	field ScalarField3D
+	kind  FractalKind
+	oct   Octaves
+}
+
+// NewFractal3D wraps field in a fractal sum of the given kind.
+func NewFractal3D(field ScalarField3D, kind FractalKind, oct Octaves) *Fractal3D {
+	return &Fractal3D{field: field, kind: kind, oct: oct}
+}
+
+// At returns the fractal value at (x,y,z), in [-1,1].
+func (f *Fractal3D) At(x, y, z float64) float64 {
+	return sumOctaves(f.kind, f.oct, func(freq, shift float64) float64 {
+		return f.field.At(x*freq+shift, y*freq+shift, z*freq+shift)
+	})
+}
+
+// sumOctaves adds up oct.N samples of the kind's octave shape and
+// normalizes by the total amplitude, so the result stays in [-1,1]
+// whatever the octave count or gain.
+func sumOctaves(kind FractalKind, oct Octaves, sample func(freq, shift float64) float64) float64 {
+	n := max(1, oct.N)
+	sum, norm := 0.0, 0.0
+	amp, freq := 1.0, 1.0
+	weight := 1.0 // ridged only
+
+	for i := 0; i < n; i++ {
+		v := sample(freq, float64(i)*octaveShift)
+		switch kind {
+		case Ridged:
+			v = 1 - math.Abs(v)
+			v *= v * weight
+			weight = math.Max(0, math.Min(1, 2*v))
+			v = 2*v - 1
+		case Turbulence:
+			v = math.Abs(v)
+		case Billow:
+			v = 2*math.Abs(v) - 1
+		}
+		sum += v * amp
+		norm += amp
+		amp *= oct.Gain
+		freq *= oct.Lacunarity
+	}
+	return sum / norm
+}
+
+// FractalKinds lists the options of the "noise" param, in index order.
+// "none" leaves an engine's field as it is.
+var FractalKinds = []string{"none", string(FBM), string(Ridged), string(Turbulence), string(Billow)}
+
+// FractalParams are the params an engine adds to its spec to let
+// configs layer its noise; see FractalFor.
+var FractalParams = []core.Param{
+	core.EnumParam("noise", "none", FractalKinds, "layer the noise field: fbm, ridged, turbulence or billow"),
+	core.IntParam("octaves", DefaultOctaves.N, 1, 16, "number of noise layers"),
+	core.FloatParam("lacunarity", DefaultOctaves.Lacunarity, 1, 8, "frequency multiplier between layers"),
+	core.FloatParam("gain", DefaultOctaves.Gain, 0, 1, "amplitude multiplier between layers"),
+}
+
+// fractalFromParams reads FractalParams; ok is false for "none".
+func fractalFromParams(params map[string]float64) (kind FractalKind, oct Octaves, ok bool) {
+	i := int(params["noise"])
+	if i <= 0 || i >= len(FractalKinds) {
+		return "", Octaves{}, false
+	}
+	oct = Octaves{N: int(params["octaves"]), Lacunarity: params["lacunarity"], Gain: params["gain"]}
+	return FractalKind(FractalKinds[i]), oct, true
+}
+
+// FractalFor wraps field as configured by params that include
+// FractalParams with defaults filled in.
+func FractalFor(field ScalarField2D, params map[string]float64) ScalarField2D {
+	kind, oct, ok := fractalFromParams(params)
+	if !ok {
+		return field
+	}
+	return NewFractal2D(field, kind, oct)
+}
+
+// FractalFor3D is FractalFor for 3D fields.
+func FractalFor3D(field ScalarField3D, params map[string]float64) ScalarField3D {
+	kind, oct, ok := fractalFromParams(params)
+	if !ok {
+		return field
+	}
+	return NewFractal3D(field, kind, oct)
+}
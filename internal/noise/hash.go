@@ -0,0 +1,20 @@
+package noise
+
+// hash mixes seed and lattice coordinates into 64 well-scrambled bits
+// (splitmix64 finalizer), so lattice noises need no permutation tables.
+func hash(seed int64, coords ...int) uint64 {
+	h := uint64(seed)
+	for _, c := range coords {
+		h ^= uint64(int64(c))
+		h += 0x9e3779b97f4a7c15
+		h = (h ^ (h >> 30)) * 0xbf58476d1ce4e5b9
+		h = (h ^ (h >> 27)) * 0x94d049bb133111eb
+		h ^= h >> 31
+	}
+	return h
+}
+
+// unit returns the i-th 21-bit slice of h as a value in [0,1).
+func unit(h uint64, i int) float64 {
+	return float64((h>>(21*i))&(1<<21-1)) / (1 << 21)
+}
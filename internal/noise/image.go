@@ -0,0 +1,237 @@
+package noise
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/jpeg"
+	_ "image/png"
+	"math"
+	"os"
+
+	"genart/internal/core"
+)
+
+// Channel selects what an ImageField reads from each pixel.
+type Channel string
+
+const (
+	Luminance Channel = "luminance" // Rec. 709 luma
+	Red       Channel = "red"
+	Green     Channel = "green"
+	Blue      Channel = "blue"
+	Alpha     Channel = "alpha"
+	Hue       Channel = "hue"   // OKLCH hue, 0° to 360°
+	Edges     Channel = "edges" // Sobel gradient magnitude of luminance, relative to the strongest edge
+)
+
+// Fit places an image on the unit canvas.
+type Fit string
+
+const (
+	Stretch Fit = "stretch" // fill the canvas, distorting the image's aspect ratio
+	Contain Fit = "contain" // show the whole image, centered; the rest reads as ImageOptions.Outside
+	Cover   Fit = "cover"   // fill the canvas, centered, cropping what sticks out
+)
+
+// ImageOptions configures an ImageField. The zero value reads
+// stretched, unblurred luminance.
+type ImageOptions struct {
+	Channel Channel
+	Fit     Fit
+	Aspect  float64 // canvas width/height for contain and cover; 0 means 1
+	Blur    float64 // blur radius in image pixels
+	Invert  bool    // flip the values: dark reads high
+	Outside float64 // value outside the image with Contain
+}
+
+// ImageField reads an image as a ScalarField2D over the unit canvas,
+// with values in [-1,1] and bilinear sampling between pixels.
+type ImageField struct {
+	vals    []float64 // one channel in [0,1], row-major
+	w, h    int
+	x0, y0  float64 // canvas origin in image pixels
+	sx, sy  float64 // image pixels per canvas unit
+	outside float64
+}
+
+// LoadImageField decodes the PNG or JPEG at path; see NewImageField.
+func LoadImageField(path string, opt ImageOptions) (*ImageField, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	img, _, err := image.Decode(f)
+	if err != nil {
+		return nil, fmt.Errorf("decode %s: %w", path, err)
+	}
+	return NewImageField(img, opt)
+}
+
+// NewImageField reads img's opt.Channel, blurred by opt.Blur and placed
+// on the canvas by opt.Fit.
+func NewImageField(img image.Image, opt ImageOptions) (*ImageField, error) {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w == 0 || h == 0 {
+		return nil, fmt.Errorf("image field: empty image")
+	}
+
+	read, err := channelReader(opt.Channel)
+	if err != nil {
+		return nil, err
+	}
+	if opt.Channel == Edges {
+		read, _ = channelReader(Luminance)
+	}
+	vals := make([]float64, w*h)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			c := color.NRGBA64Model.Convert(img.At(b.Min.X+x, b.Min.Y+y)).(color.NRGBA64)
+			vals[y*w+x] = read(core.RGBA{
+				R: float64(c.R) / 0xffff,
+				G: float64(c.G) / 0xffff,
+				B: float64(c.B) / 0xffff,
+				A: float64(c.A) / 0xffff,
+			})
+		}
+	}
+	if r := int(math.Round(opt.Blur)); r > 0 {
+		blur(vals, w, h, r)
+	}
+	if opt.Channel == Edges {
+		vals = sobel(vals, w, h)
+	}
+	if opt.Invert {
+		for i, v := range vals {
+			vals[i] = 1 - v
+		}
+	}
+
+	f := &ImageField{vals: vals, w: w, h: h, outside: opt.Outside}
+	if err := f.fit(opt.Fit, opt.Aspect); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func channelReader(ch Channel) (func(core.RGBA) float64, error) {
+	switch ch {
+	case "", Luminance, Edges:
+		return func(c core.RGBA) float64 { return 0.2126*c.R + 0.7152*c.G + 0.0722*c.B }, nil
+	case Red:
+		return func(c core.RGBA) float64 { return c.R }, nil
+	case Green:
+		return func(c core.RGBA) float64 { return c.G }, nil
+	case Blue:
+		return func(c core.RGBA) float64 { return c.B }, nil
+	case Alpha:
+		return func(c core.RGBA) float64 { return c.A }, nil
+	case Hue:
+		return func(c core.RGBA) float64 {
+			_, _, hue := c.OKLCH()
+			return hue / 360
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown image channel %q (available: luminance, red, green, blue, alpha, hue, edges)", ch)
+	}
+}
+
+// fit sets the mapping from canvas units to image pixels.
+func (f *ImageField) fit(fit Fit, aspect float64) error {
+	if aspect <= 0 {
+		aspect = 1
+	}
+	w, h := float64(f.w), float64(f.h)
+	switch fit {
+	case "", Stretch:
+		f.sx, f.sy = w, h
+		return nil
+	case Contain, Cover:
+	default:
+		return fmt.Errorf("unknown image fit %q (available: stretch, contain, cover)", fit)
+	}
+
+	// image pixels per canvas unit along y; the canvas is aspect×1
+	s := math.Max(w/aspect, h)
+	if fit == Cover {
+		s = math.Min(w/aspect, h)
+	}
+	f.sx, f.sy = s*aspect, s
+	f.x0, f.y0 = (w-f.sx)/2, (h-f.sy)/2
+	return nil
+}
+
+// At returns the channel at canvas point (x,y), in [-1,1].
+func (f *ImageField) At(x, y float64) float64 {
+	// pixel centers sit at half-integers
+	u := f.x0 + x*f.sx - 0.5
+	v := f.y0 + y*f.sy - 0.5
+	if u < -0.5 || v < -0.5 || u > float64(f.w)-0.5 || v > float64(f.h)-0.5 {
+		return f.outside
+	}
+
+	u = math.Max(0, math.Min(float64(f.w-1), u))
+	v = math.Max(0, math.Min(float64(f.h-1), v))
+	i, j := min(int(u), max(0, f.w-2)), min(int(v), max(0, f.h-2))
+	tx, ty := u-float64(i), v-float64(j)
+	i1, j1 := min(i+1, f.w-1), min(j+1, f.h-1)
+
+	val := lerp(
+		lerp(f.vals[j*f.w+i], f.vals[j*f.w+i1], tx),
+		lerp(f.vals[j1*f.w+i], f.vals[j1*f.w+i1], tx),
+		ty,
+	)
+	return 2*val - 1
+}
+
+// blur approximates a Gaussian of radius r with three box blurs,
+// separably, clamping at the borders.
+func blur(vals []float64, w, h, r int) {
+	tmp := make([]float64, len(vals))
+	for pass := 0; pass < 3; pass++ {
+		boxBlur(vals, tmp, w, h, r, 1, w) // rows
+		boxBlur(tmp, vals, h, w, r, w, 1) // columns
+	}
+}
+
+// boxBlur averages src over 2r+1 samples along lines of n samples
+// spaced step apart; lines start stride apart. Results go to dst.
+func boxBlur(src, dst []float64, n, lines, r, step, stride int) {
+	for l := 0; l < lines; l++ {
+		base := l * stride
+		at := func(i int) float64 { return src[base+max(0, min(i, n-1))*step] }
+		sum := 0.0
+		for i := -r; i <= r; i++ {
+			sum += at(i)
+		}
+		for i := 0; i < n; i++ {
+			dst[base+i*step] = sum / float64(2*r+1)
+			sum += at(i+r+1) - at(i-r)
+		}
+	}
+}
+
+// sobel returns the gradient magnitude of vals, scaled so the
+// strongest edge is 1.
+func sobel(vals []float64, w, h int) []float64 {
+	at := func(x, y int) float64 { return vals[max(0, min(y, h-1))*w+max(0, min(x, w-1))] }
+	out := make([]float64, len(vals))
+	peak := 0.0
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			gx := at(x+1, y-1) + 2*at(x+1, y) + at(x+1, y+1) - at(x-1, y-1) - 2*at(x-1, y) - at(x-1, y+1)
+			gy := at(x-1, y+1) + 2*at(x, y+1) + at(x+1, y+1) - at(x-1, y-1) - 2*at(x, y-1) - at(x+1, y-1)
+			m := math.Hypot(gx, gy)
+			out[y*w+x] = m
+			peak = math.Max(peak, m)
+		}
+	}
+	if peak > 0 {
+		for i := range out {
+			out[i] /= peak
+		}
+	}
+	return out
+}
@@ -0,0 +1,303 @@
+package noise
+
+import "math"
+
+// In-house improved Perlin noise (Perlin 2002: quintic fade, fixed
+// gradient sets). Unlike PerlinField it depends on nothing outside
+// this package, so a seed renders the same for as long as this file
+// is left alone. Changes that alter output must go into a new version
+// instead; see PerlinVersions.
+
+// perm is a seeded permutation of 0..255, doubled to skip wrapping.
+type perm [512]uint8
+
+// newPerm shuffles 0..255 (Fisher-Yates) with the package hash, not
+// math/rand, so the table cannot change under us.
+func newPerm(seed int64) *perm {
+	var p perm
+	for i := 0; i < 256; i++ {
+		p[i] = uint8(i)
+	}
+	for i := 255; i > 0; i-- {
+		j := int(hash(seed, i) % uint64(i+1))
+		p[i], p[j] = p[j], p[i]
+	}
+	copy(p[256:], p[:256])
+	return &p
+}
+
+// perlinOctaves sums octaves of a single-octave noise, each at twice the
+// frequency and persistence times the amplitude of the previous one,
+// normalized by the total amplitude.
+type perlinOctaves struct {
+	p           *perm
+	scale       float64
+	octaves     int
+	persistence float64
+}
+
+func (o perlinOctaves) sum(noise func(freq float64) float64) float64 {
+	if o.scale <= 0 {
+		return 0
+	}
+	sum, norm := 0.0, 0.0
+	amp, freq := 1.0, 1/o.scale
+	for i := 0; i < max(1, o.octaves); i++ {
+		sum += amp * noise(freq)
+		norm += amp
+		amp *= o.persistence
+		freq *= 2
+	}
+	return sum / norm
+}
+
+// Perlin2D is in-house 2D Perlin noise, roughly in [-1,1].
+type Perlin2D struct{ perlinOctaves }
+
+// NewPerlin2D creates a 2D Perlin field.
+//
+// seed        → determinism
+// scale       → controls "zoom" (smaller = zoom in, larger = zoom out)
+// octaves     → layers of detail, each at double the frequency
+// persistence → amplitude kept from one octave to the next
+func NewPerlin2D(seed int64, scale float64, octaves int, persistence float64) *Perlin2D {
+	return &Perlin2D{perlinOctaves{newPerm(seed), scale, octaves, persistence}}
+}
+
+func (n *Perlin2D) At(x, y float64) float64 {
+	return n.sum(func(f float64) float64 { return n.p.noise2(x*f, y*f) })
+}
+
+// Gradient returns the exact partial derivatives of the field at (x,y),
+// making Perlin2D a Differentiable2D.
+func (n *Perlin2D) Gradient(x, y float64) (dx, dy float64) {
+	if n.scale <= 0 {
+		return 0, 0
+	}
+	norm := 0.0
+	amp, freq := 1.0, 1/n.scale
+	for i := 0; i < max(1, n.octaves); i++ {
+		gx, gy := n.p.deriv2(x*freq, y*freq)
+		// chain rule: d/dx noise(x·freq) = freq·noise'(x·freq)
+		dx += amp * freq * gx
+		dy += amp * freq * gy
+		norm += amp
+		amp *= n.persistence
+		freq *= 2
+	}
+	return dx / norm, dy / norm
+}
+
+// Perlin3D is in-house 3D Perlin noise, roughly in [-1,1].
+type Perlin3D struct{ perlinOctaves }
+
+func NewPerlin3D(seed int64, scale float64, octaves int, persistence float64) *Perlin3D {
+	return &Perlin3D{perlinOctaves{newPerm(seed), scale, octaves, persistence}}
+}
+
+func (n *Perlin3D) At(x, y, z float64) float64 {
+	return n.sum(func(f float64) float64 { return n.p.noise3(x*f, y*f, z*f) })
+}
+
+// Perlin4D is in-house 4D Perlin noise, roughly in [-1,1].
+type Perlin4D struct{ perlinOctaves }
+
+func NewPerlin4D(seed int64, scale float64, octaves int, persistence float64) *Perlin4D {
+	return &Perlin4D{perlinOctaves{newPerm(seed), scale, octaves, persistence}}
+}
+
+func (n *Perlin4D) At(x, y, z, w float64) float64 {
+	return n.sum(func(f float64) float64 { return n.p.noise4(x*f, y*f, z*f, w*f) })
+}
+
+// cell splits a coordinate into its lattice index (mod 256) and the
+// offset within the cell.
+func cell(v float64) (int, float64) {
+	f := math.Floor(v)
+	return int(f) & 255, v - f
+}
+
+func (p *perm) noise2(x, y float64) float64 {
+	xi, xf := cell(x)
+	yi, yf := cell(y)
+	u, v := fade(xf), fade(yf)
+
+	a, b := int(p[xi])+yi, int(p[xi+1])+yi
+	return lerp(
+		lerp(grad2(p[a], xf, yf), grad2(p[b], xf-1, yf), u),
+		lerp(grad2(p[a+1], xf, yf-1), grad2(p[b+1], xf-1, yf-1), u),
+		v,
+	)
+}
+
+// deriv2 returns the partial derivatives of noise2 at (x,y).
+func (p *perm) deriv2(x, y float64) (dx, dy float64) {
+	xi, xf := cell(x)
+	yi, yf := cell(y)
+	u, v := fade(xf), fade(yf)
+	du, dv := fadeDeriv(xf), fadeDeriv(yf)
+
+	a, b := int(p[xi])+yi, int(p[xi+1])+yi
+	// corner values and their gradient vectors, which are also the
+	// derivatives of the corner values
+	n00, n10 := grad2(p[a], xf, yf), grad2(p[b], xf-1, yf)
+	n01, n11 := grad2(p[a+1], xf, yf-1), grad2(p[b+1], xf-1, yf-1)
+	g00x, g00y := grad2Vec(p[a])
+	g10x, g10y := grad2Vec(p[b])
+	g01x, g01y := grad2Vec(p[a+1])
+	g11x, g11y := grad2Vec(p[b+1])
+
+	// noise2 = n00 + u(n10-n00) + v(n01-n00) + uv(n00-n10-n01+n11)
+	k := n00 - n10 - n01 + n11
+	dx = g00x + u*(g10x-g00x) + v*(g01x-g00x) + u*v*(g00x-g10x-g01x+g11x) +
+		du*(n10-n00+v*k)
+	dy = g00y + u*(g10y-g00y) + v*(g01y-g00y) + u*v*(g00y-g10y-g01y+g11y) +
+		dv*(n01-n00+u*k)
+	return dx, dy
+}
+
+func (p *perm) noise3(x, y, z float64) float64 {
+	xi, xf := cell(x)
+	yi, yf := cell(y)
+	zi, zf := cell(z)
+	u, v, w := fade(xf), fade(yf), fade(zf)
+
+	a := int(p[xi]) + yi
+	aa, ab := int(p[a])+zi, int(p[a+1])+zi
+	b := int(p[xi+1]) + yi
+	ba, bb := int(p[b])+zi, int(p[b+1])+zi
+
+	return lerp(
+		lerp(
+			lerp(grad3(p[aa], xf, yf, zf), grad3(p[ba], xf-1, yf, zf), u),
+			lerp(grad3(p[ab], xf, yf-1, zf), grad3(p[bb], xf-1, yf-1, zf), u),
+			v),
+		lerp(
+			lerp(grad3(p[aa+1], xf, yf, zf-1), grad3(p[ba+1], xf-1, yf, zf-1), u),
+			lerp(grad3(p[ab+1], xf, yf-1, zf-1), grad3(p[bb+1], xf-1, yf-1, zf-1), u),
+			v),
+		w,
+	)
+}
+
+func (p *perm) noise4(x, y, z, w float64) float64 {
+	var i [4]int
+	var f [4]float64
+	i[0], f[0] = cell(x)
+	i[1], f[1] = cell(y)
+	i[2], f[2] = cell(z)
+	i[3], f[3] = cell(w)
+
+	// corner c has bit k set when it is on the far side along axis k
+	var vals [16]float64
+	for c := 0; c < 16; c++ {
+		h := 0
+		var d [4]float64
+		for k := 0; k < 4; k++ {
+			bit := c >> k & 1
+			h = int(p[h+i[k]+bit])
+			d[k] = f[k] - float64(bit)
+		}
+		vals[c] = grad4(uint8(h), d[0], d[1], d[2], d[3])
+	}
+	// collapse one axis at a time
+	for k, n := 0, 16; k < 4; k, n = k+1, n/2 {
+		t := fade(f[k])
+		for c := 0; c < n/2; c++ {
+			vals[c] = lerp(vals[2*c], vals[2*c+1], t)
+		}
+	}
+	return vals[0]
+}
+
+// grad2 dots (x,y) with one of 8 gradients: the axes and diagonals.
+func grad2(h uint8, x, y float64) float64 {
+	switch h & 7 {
+	case 0:
+		return x + y
+	case 1:
+		return -x + y
+	case 2:
+		return x - y
+	case 3:
+		return -x - y
+	case 4:
+		return x
+	case 5:
+		return -x
+	case 6:
+		return y
+	default:
+		return -y
+	}
+}
+
+// grad2Vec is the gradient grad2 dots with.
+func grad2Vec(h uint8) (float64, float64) {
+	switch h & 7 {
+	case 0:
+		return 1, 1
+	case 1:
+		return -1, 1
+	case 2:
+		return 1, -1
+	case 3:
+		return -1, -1
+	case 4:
+		return 1, 0
+	case 5:
+		return -1, 0
+	case 6:
+		return 0, 1
+	default:
+		return 0, -1
+	}
+}
+
+// grad3 dots (x,y,z) with one of the 12 cube edge directions (plus 4
+// repeats), as in Perlin's reference implementation.
+func grad3(h uint8, x, y, z float64) float64 {
+	h &= 15
+	u := y
+	if h < 8 {
+		u = x
+	}
+	v := z
+	if h < 4 {
+		v = y
+	} else if h == 12 || h == 14 {
+		v = x
+	}
+	if h&1 != 0 {
+		u = -u
+	}
+	if h&2 != 0 {
+		v = -v
+	}
+	return u + v
+}
+
+// grad4 dots (x,y,z,w) with one of the 32 edge directions of a
+// tesseract: three of ±1 with the fourth axis zero.
+func grad4(h uint8, x, y, z, w float64) float64 {
+	h &= 31
+	a, b, c := y, z, w
+	switch h >> 3 {
+	case 1:
+		a, b, c = x, z, w
+	case 2:
+		a, b, c = x, y, w
+	case 3:
+		a, b, c = x, y, z
+	}
+	if h&1 != 0 {
+		a = -a
+	}
+	if h&2 != 0 {
+		b = -b
+	}
+	if h&4 != 0 {
+		c = -c
+	}
+	return a + b + c
+}
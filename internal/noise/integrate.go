@@ -0,0 +1,109 @@
+package noise
+
+import (
+	"math"
+
+	"genart/internal/core"
+)
+
+// Method is a numerical scheme for following a VectorField2D.
+type Method string
+
+const (
+	Euler    Method = "euler"    // one sample per step; drifts off curved paths
+	Midpoint Method = "midpoint" // two samples per step
+	RK4      Method = "rk4"      // four samples per step; stays on curves at large steps
+)
+
+// Integrator moves points through a vector field. With Tolerance > 0
+// the step adapts: it halves while one full step and two half steps
+// disagree by more than Tolerance, and grows again where they agree,
+// staying within [MinStep, MaxStep].
+type Integrator struct {
+	Method    Method
+	Tolerance float64
+	MinStep   float64
+	MaxStep   float64
+}
+
+// Advance moves (x,y) one step of size h through f. It returns the new
+// point and the step size to use next, which is h unless adaptive.
+func (in Integrator) Advance(f VectorField2D, x, y, h float64) (nx, ny, next float64) {
+	if in.Tolerance <= 0 {
+		nx, ny = in.step(f, x, y, h)
+		return nx, ny, h
+	}
+
+	for {
+		x1, y1 := in.step(f, x, y, h)
+		xm, ym := in.step(f, x, y, h/2)
+		x2, y2 := in.step(f, xm, ym, h/2)
+		err := math.Hypot(x2-x1, y2-y1)
+
+		if err > in.Tolerance && h/2 >= in.MinStep {
+			h /= 2
+			continue
+		}
+		next = h
+		if err < in.Tolerance/4 {
+			next = math.Min(2*h, in.MaxStep)
+		}
+		return x2, y2, next
+	}
+}
+
+func (in Integrator) step(f VectorField2D, x, y, h float64) (float64, float64) {
+	switch in.Method {
+	case Midpoint:
+		k1x, k1y := f.At(x, y)
+		k2x, k2y := f.At(x+h/2*k1x, y+h/2*k1y)
+		return x + h*k2x, y + h*k2y
+	case RK4:
+		k1x, k1y := f.At(x, y)
+		k2x, k2y := f.At(x+h/2*k1x, y+h/2*k1y)
+		k3x, k3y := f.At(x+h/2*k2x, y+h/2*k2y)
+		k4x, k4y := f.At(x+h*k3x, y+h*k3y)
+		return x + h/6*(k1x+2*k2x+2*k3x+k4x), y + h/6*(k1y+2*k2y+2*k3y+k4y)
+	default:
+		dx, dy := f.At(x, y)
+		return x + dx*h, y + dy*h
+	}
+}
+
+// Trace follows f from (x,y) for up to n steps of initial size h and
+// returns the visited points, starting with (x,y). It stops early when
+// keep reports false for the next point, or when the field stalls.
+func (in Integrator) Trace(f VectorField2D, x, y, h float64, n int, keep func(x, y float64) bool) []core.Vec2 {
+	points := []core.Vec2{{X: x, Y: y}}
+	for i := 0; i < n; i++ {
+		nx, ny, next := in.Advance(f, x, y, h)
+		if !keep(nx, ny) || (nx == x && ny == y) {
+			break
+		}
+		x, y, h = nx, ny, next
+		points = append(points, core.Vec2{X: x, Y: y})
+	}
+	return points
+}
+
+// IntegratorParams are the params an engine adds to its spec to let
+// configs choose how particles are stepped; see IntegratorFor.
+var (
+	Methods          = []string{string(Euler), string(Midpoint), string(RK4)}
+	IntegratorParams = []core.Param{
+		core.EnumParam("integrator", string(Euler), Methods, "stepping scheme: euler, midpoint or rk4"),
+		core.FloatParam("tolerance", 0, 0, 1, "adaptive step error tolerance (0 = fixed step)"),
+	}
+)
+
+// IntegratorFor builds the Integrator configured by params that include
+// IntegratorParams with defaults filled in. Adaptive steps range from
+// step/16 to step·8.
+func IntegratorFor(params map[string]float64, step float64) Integrator {
+	return Integrator{
+		Method:    Method(Methods[int(params["integrator"])]),
+		Tolerance: params["tolerance"],
+		MinStep:   step / 16,
+		MaxStep:   step * 8,
+	}
+}
@@ -0,0 +1,47 @@
+package noise
+
+import "math"
+
+// Loop2D is a 2D slice through a 4D field whose remaining two
+// coordinates lie on a circle. Moving t from 0 to 1 morphs the slice
+// continuously and returns exactly to where it started.
+type Loop2D struct {
+	field ScalarField4D
+	z, w  float64
+}
+
+// NewLoop2D samples field at phase t in [0,1) of a circle with the
+// given radius (larger = more change per loop).
+func NewLoop2D(field ScalarField4D, t, radius float64) Loop2D {
+	z, w := loopPoint(t, radius)
+	return Loop2D{field: field, z: z, w: w}
+}
+
+// At returns the field value at (x,y) for this phase.
+func (l Loop2D) At(x, y float64) float64 {
+	return l.field.At(x, y, l.z, l.w)
+}
+
+// Loop3D is a 3D slice through a 4D field that walks a circle in the
+// (z,w) plane, offset by the z coordinate passed to At.
+type Loop3D struct {
+	field ScalarField4D
+	z, w  float64
+}
+
+// NewLoop3D samples field at phase t in [0,1) of a circle with the
+// given radius.
+func NewLoop3D(field ScalarField4D, t, radius float64) Loop3D {
+	z, w := loopPoint(t, radius)
+	return Loop3D{field: field, z: z, w: w}
+}
+
+// At returns the field value at (x,y,z) for this phase.
+func (l Loop3D) At(x, y, z float64) float64 {
+	return l.field.At(x, y, z+l.z, l.w)
+}
+
+func loopPoint(t, radius float64) (float64, float64) {
+	theta := 2 * math.Pi * t
+	return radius * math.Cos(theta), radius * math.Sin(theta)
+}
@@ -13,3 +13,10 @@ type ScalarField3D interface {
 	// Typical contract: output in [-1,1].
 	At(x, y, z float64) float64
 }
+
+// ScalarField4D is a contract for any scalar-valued 4D field.
+type ScalarField4D interface {
+	// At returns the field value at (x,y,z,w).
+	// Typical contract: output in [-1,1].
+	At(x, y, z, w float64) float64
+}
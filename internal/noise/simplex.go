@@ -47,3 +47,24 @@ func (s *SimplexField3D) At(x, y, z float64) float64 {
 	}
 	return s.noise.Eval3(x/s.scale, y/s.scale, z/s.scale)
 }
+
+// --- 4D variant ---
+
+type SimplexField4D struct {
+	noise opensimplex.Noise
+	scale float64
+}
+
+func NewSimplexField4D(seed int64, scale float64) *SimplexField4D {
+	return &SimplexField4D{
+		noise: opensimplex.New(seed),
+		scale: scale,
+	}
+}
+
+func (s *SimplexField4D) At(x, y, z, w float64) float64 {
+	if s.scale <= 0 {
+		return 0
+	}
+	return s.noise.Eval4(x/s.scale, y/s.scale, z/s.scale, w/s.scale)
+}
@@ -0,0 +1,51 @@
+package noise
+
+import (
+	"fmt"
+	"math"
+)
+
+// Tile2D is a 2D field that repeats every period in x and y. It walks
+// a torus through a 4D field: x and y each map to an angle around one
+// of two circles, so opposite edges of a tile sample the same points.
+type Tile2D struct {
+	field  ScalarField4D
+	period float64
+	radius float64
+}
+
+// NewTile2D makes field periodic with the given period. scale sets the
+// zoom like it does for the other fields: one period spans about
+// period/scale noise units. field should be unscaled (scale 1).
+func NewTile2D(field ScalarField4D, period, scale float64) *Tile2D {
+	return &Tile2D{field: field, period: period, radius: period / (2 * math.Pi * scale)}
+}
+
+// At returns the field value at (x,y); At(x+period, y) == At(x, y).
+func (t *Tile2D) At(x, y float64) float64 {
+	if t.period <= 0 {
+		return 0
+	}
+	a := 2 * math.Pi * x / t.period
+	b := 2 * math.Pi * y / t.period
+	return t.field.At(
+		t.radius*math.Cos(a), t.radius*math.Sin(a),
+		t.radius*math.Cos(b), t.radius*math.Sin(b),
+	)
+}
+
+// Wrap returns v wrapped into [0,1), for toroidal particle positions.
+func Wrap(v float64) float64 {
+	return v - math.Floor(v)
+}
+
+// CheckTileable reports whether FieldFor keeps a periodic field
+// periodic under params: every octave must fit a whole number of times
+// into the period, so with layered noise the lacunarity has to be an
+// integer.
+func CheckTileable(params map[string]float64) error {
+	if _, oct, ok := fractalFromParams(params); ok && oct.Lacunarity != math.Trunc(oct.Lacunarity) {
+		return fmt.Errorf("tile needs an integer lacunarity, got %g", oct.Lacunarity)
+	}
+	return nil
+}
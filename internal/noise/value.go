@@ -0,0 +1,98 @@
+package noise
+
+import "math"
+
+// ValueField is value noise: random values on the integer lattice,
+// smoothly interpolated. Blockier than gradient noise, with its
+// extremes on the lattice points.
+type ValueField struct {
+	seed   int64
+	scale  float64
+	period int // lattice cells per repeat, 0 = no repeat
+}
+
+// NewValueField creates a 2D value noise field.
+//
+// seed  → determinism
+// scale → controls "zoom" (smaller = zoom in, larger = zoom out)
+func NewValueField(seed int64, scale float64) *ValueField {
+	return &ValueField{seed: seed, scale: scale}
+}
+
+// NewTiledValueField creates value noise that repeats every 1 unit in x
+// and y, with cells lattice cells per repeat.
+func NewTiledValueField(seed int64, cells int) *ValueField {
+	return &ValueField{seed: seed, scale: 1 / float64(cells), period: cells}
+}
+
+// At returns a noise value at (x,y) in [-1,1].
+func (v *ValueField) At(x, y float64) float64 {
+	if v.scale <= 0 {
+		return 0
+	}
+	x, y = x/v.scale, y/v.scale
+	x0, y0 := math.Floor(x), math.Floor(y)
+	ix, iy := int(x0), int(y0)
+	tx, ty := fade(x-x0), fade(y-y0)
+
+	at := func(dx, dy int) float64 {
+		return 2*unit(hash(v.seed, wrapCell(ix+dx, v.period), wrapCell(iy+dy, v.period)), 0) - 1
+	}
+	return lerp(
+		lerp(at(0, 0), at(1, 0), tx),
+		lerp(at(0, 1), at(1, 1), tx),
+		ty,
+	)
+}
+
+// ValueField3D is ValueField in three dimensions.
+type ValueField3D struct {
+	seed  int64
+	scale float64
+}
+
+func NewValueField3D(seed int64, scale float64) *ValueField3D {
+	return &ValueField3D{seed: seed, scale: scale}
+}
+
+func (v *ValueField3D) At(x, y, z float64) float64 {
+	if v.scale <= 0 {
+		return 0
+	}
+	x, y, z = x/v.scale, y/v.scale, z/v.scale
+	x0, y0, z0 := math.Floor(x), math.Floor(y), math.Floor(z)
+	ix, iy, iz := int(x0), int(y0), int(z0)
+	tx, ty, tz := fade(x-x0), fade(y-y0), fade(z-z0)
+
+	at := func(dx, dy, dz int) float64 { return 2*unit(hash(v.seed, ix+dx, iy+dy, iz+dz), 0) - 1 }
+	plane := func(dz int) float64 {
+		return lerp(
+			lerp(at(0, 0, dz), at(1, 0, dz), tx),
+			lerp(at(0, 1, dz), at(1, 1, dz), tx),
+			ty,
+		)
+	}
+	return lerp(plane(0), plane(1), tz)
+}
+
+// fade is the quintic smootherstep, flat in value and slope at 0 and 1.
+func fade(t float64) float64 {
+	return t * t * t * (t*(t*6-15) + 10)
+}
+
+// fadeDeriv is the derivative of fade.
+func fadeDeriv(t float64) float64 {
+	return 30 * t * t * (t - 1) * (t - 1)
+}
+
+func lerp(a, b, t float64) float64 {
+	return a + (b-a)*t
+}
+
+// wrapCell maps lattice index i into [0,period); period 0 leaves it.
+func wrapCell(i, period int) int {
+	if period <= 0 {
+		return i
+	}
+	return ((i % period) + period) % period
+}
@@ -0,0 +1,135 @@
+package noise
+
+import "math"
+
+// VectorField2D gives a direction and speed at every point.
+type VectorField2D interface {
+	At(x, y float64) (dx, dy float64)
+}
+
+// Differentiable2D is a ScalarField2D that knows its exact partial
+// derivatives, such as Perlin2D.
+type Differentiable2D interface {
+	ScalarField2D
+	Gradient(x, y float64) (dx, dy float64)
+}
+
+// defaultEps is the finite difference step for fields without exact
+// derivatives when no step is given.
+const defaultEps = 1e-4
+
+// CurlField is the curl of a scalar field: it flows along the field's
+// contour lines, so particles swirl without bunching up. With Eps 0 and
+// a Differentiable2D field the curl is exact; otherwise it is taken by
+// central differences (see Curl2D), which is what engines do so that
+// archived seeds keep rendering the same.
+type CurlField struct {
+	Field ScalarField2D
+	Eps   float64 // finite difference step; 0 for exact derivatives
+}
+
+func (c CurlField) At(x, y float64) (float64, float64) {
+	if c.Eps <= 0 {
+		if d, ok := c.Field.(Differentiable2D); ok {
+			dx, dy := d.Gradient(x, y)
+			return dy, -dx
+		}
+		return Curl2D(c.Field, x, y, defaultEps)
+	}
+	return Curl2D(c.Field, x, y, c.Eps)
+}
+
+// GradientField points uphill on a scalar field. Like CurlField, it is
+// exact with Eps 0 and a Differentiable2D field, and otherwise uses
+// central differences; see Gradient2D.
+type GradientField struct {
+	Field ScalarField2D
+	Eps   float64 // finite difference step; 0 for exact derivatives
+}
+
+func (g GradientField) At(x, y float64) (float64, float64) {
+	if g.Eps <= 0 {
+		if d, ok := g.Field.(Differentiable2D); ok {
+			return d.Gradient(x, y)
+		}
+		return Gradient2D(g.Field, x, y, defaultEps)
+	}
+	return Gradient2D(g.Field, x, y, g.Eps)
+}
+
+// AngleField reads a scalar field as a heading: a value v points at
+// angle v·Turns·π, at unit speed.
+type AngleField struct {
+	Field ScalarField2D
+	Turns float64
+}
+
+func (a AngleField) At(x, y float64) (float64, float64) {
+	angle := a.Field.At(x, y) * a.Turns * math.Pi
+	return math.Cos(angle), math.Sin(angle)
+}
+
+// ScaledField samples Field at (x,y)·Factor, the way engines zoom into
+// noise with a frequency param.
+type ScaledField struct {
+	Field  VectorField2D
+	Factor float64
+}
+
+func (s ScaledField) At(x, y float64) (float64, float64) {
+	return s.Field.At(x*s.Factor, y*s.Factor)
+}
+
+// Weighted is one term of a SumField.
+type Weighted struct {
+	Field  VectorField2D
+	Weight float64
+}
+
+// SumField adds up weighted fields.
+type SumField []Weighted
+
+func (s SumField) At(x, y float64) (float64, float64) {
+	var dx, dy float64
+	for _, w := range s {
+		vx, vy := w.Field.At(x, y)
+		dx += w.Weight * vx
+		dy += w.Weight * vy
+	}
+	return dx, dy
+}
+
+// Blend mixes a into b by t in [0,1].
+func Blend(a, b VectorField2D, t float64) SumField {
+	return SumField{{Field: a, Weight: 1 - t}, {Field: b, Weight: t}}
+}
+
+// PointField pulls towards (X,Y), or pushes away with a negative
+// Strength. Speed is Strength at the point and falls off with distance
+// as 1/(1+(d/Radius)²).
+type PointField struct {
+	X, Y     float64
+	Strength float64
+	Radius   float64
+}
+
+// Attractor returns a PointField pulling towards (x,y).
+func Attractor(x, y, strength, radius float64) PointField {
+	return PointField{X: x, Y: y, Strength: strength, Radius: radius}
+}
+
+// Repeller returns a PointField pushing away from (x,y).
+func Repeller(x, y, strength, radius float64) PointField {
+	return PointField{X: x, Y: y, Strength: -strength, Radius: radius}
+}
+
+func (p PointField) At(x, y float64) (float64, float64) {
+	dx, dy := p.X-x, p.Y-y
+	d := math.Hypot(dx, dy)
+	if d == 0 || p.Radius <= 0 {
+		return 0, 0
+	}
+	r := d / p.Radius
+	s := p.Strength / (1 + r*r) / d
+	return dx * s, dy * s
+}
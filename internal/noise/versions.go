@@ -0,0 +1,45 @@
+package noise
+
+import (
+	"fmt"
+	"strings"
+
+	"genart/internal/core"
+)
+
+// Perlin implementation names. A name always renders the same output:
+// changes that would alter it ship under a new name instead, so saved
+// configs keep reproducing.
+const (
+	PerlinGoPerlin = "go-perlin" // github.com/aquilax/go-perlin with alpha 2, beta 2, 3 octaves
+	PerlinV1       = "perlin-v1" // in-house improved Perlin, 3 octaves, persistence 0.5
+)
+
+// PerlinVersions lists the options of the "perlin" param, in index
+// order. New versions are appended; the default stays first.
+var PerlinVersions = []string{PerlinGoPerlin, PerlinV1}
+
+// PerlinParams are the params an engine using Perlin noise adds to its
+// spec; see PerlinFor.
+var PerlinParams = []core.Param{
+	core.EnumParam("perlin", PerlinGoPerlin, PerlinVersions, "Perlin noise implementation: go-perlin or perlin-v1"),
+}
+
+// NewPerlinVersion creates the 2D Perlin field called version.
+func NewPerlinVersion(version string, seed int64, scale float64) (ScalarField2D, error) {
+	switch version {
+	case PerlinGoPerlin:
+		return NewPerlinField(seed, scale), nil
+	case PerlinV1:
+		return NewPerlin2D(seed, scale, 3, 0.5), nil
+	default:
+		return nil, fmt.Errorf("unknown perlin version %q (available: %s)", version, strings.Join(PerlinVersions, ", "))
+	}
+}
+
+// PerlinFor creates the Perlin field selected by params that include
+// PerlinParams with defaults filled in.
+func PerlinFor(seed int64, scale float64, params map[string]float64) ScalarField2D {
+	f, _ := NewPerlinVersion(PerlinVersions[int(params["perlin"])], seed, scale)
+	return f
+}
@@ -0,0 +1,77 @@
+package noise
+
+import (
+	"math"
+	"slices"
+
+	"genart/internal/core"
+)
+
+// WarpField distorts the input of one field by others:
+//
+//	At(p) = field(p + amount·(dx(p), dy(p)))
+//
+// A WarpField is itself a ScalarField2D, so warps nest: warping by a
+// warped field gives the layered look of f(p + k·g(p + k·h(p))).
+type WarpField struct {
+	field  ScalarField2D
+	dx, dy ScalarField2D
+	amount float64
+}
+
+// NewWarpField displaces field's input by amount times (dx, dy).
+func NewWarpField(field, dx, dy ScalarField2D, amount float64) *WarpField {
+	return &WarpField{field: field, dx: dx, dy: dy, amount: amount}
+}
+
+// NewWarp displaces field's input by a single warp field, sampled at
+// two far-apart offsets so the x and y displacements are unrelated.
+func NewWarp(field, warp ScalarField2D, amount float64) *WarpField {
+	return NewWarpField(field, warp, Offset2D{Field: warp, X: 5.2, Y: 1.3}, amount)
+}
+
+// At returns field at the displaced point.
+func (w *WarpField) At(x, y float64) float64 {
+	return w.field.At(x+w.amount*w.dx.At(x, y), y+w.amount*w.dy.At(x, y))
+}
+
+// Offset2D samples Field shifted by (X, Y).
+type Offset2D struct {
+	Field ScalarField2D
+	X, Y  float64
+}
+
+func (o Offset2D) At(x, y float64) float64 {
+	return o.Field.At(x+o.X, y+o.Y)
+}
+
+// WarpParams are the params an engine adds to its spec to let configs
+// warp its noise; see WarpFor.
+var WarpParams = []core.Param{
+	core.FloatParam("warp", 0, 0, math.Inf(1), "domain warp strength, in the units the field is sampled in (0 = off)"),
+	core.IntParam("warpLevels", 1, 1, 8, "nested warp passes"),
+}
+
+// WarpFor warps field by itself as configured by params that include
+// WarpParams with defaults filled in. Each level displaces field by the
+// previous level's result.
+func WarpFor(field ScalarField2D, params map[string]float64) ScalarField2D {
+	amount := params["warp"]
+	if amount == 0 {
+		return field
+	}
+	out := field
+	for i := 0; i < int(params["warpLevels"]); i++ {
+		out = NewWarp(field, out, amount)
+	}
+	return out
+}
+
+// FieldParams combines FractalParams and WarpParams.
+var FieldParams = slices.Concat(FractalParams, WarpParams)
+
+// FieldFor layers and then warps field as configured by params that
+// include FieldParams with defaults filled in.
+func FieldFor(field ScalarField2D, params map[string]float64) ScalarField2D {
+	return WarpFor(FractalFor(field, params), params)
+}
@@ -0,0 +1,153 @@
+package noise
+
+import "math"
+
+// Metric measures distance to Worley feature points.
+type Metric string
+
+const (
+	Euclidean Metric = "euclidean" // round cells
+	Manhattan Metric = "manhattan" // diamond-shaped cells
+	Chebyshev Metric = "chebyshev" // square cells
+)
+
+// CellValue selects what a Worley field returns.
+type CellValue string
+
+const (
+	F1        CellValue = "f1"    // distance to the nearest feature point: bright cell borders
+	F2        CellValue = "f2"    // distance to the second nearest
+	F2MinusF1 CellValue = "f2-f1" // zero along cell borders: cracks
+)
+
+// worleyMax is the largest distance mapped into range, per metric and
+// value; beyond it values clip to 1. The bounds sit just above the
+// largest distances seen in 2D and 3D with one feature point per unit
+// cell, so cells use the whole range without flat plateaus.
+var worleyMax = map[Metric]map[CellValue]float64{
+	Euclidean: {F1: 1.2, F2: 1.35, F2MinusF1: 1.25},
+	Manhattan: {F1: 1.7, F2: 2, F2MinusF1: 1.55},
+	Chebyshev: {F1: 1, F2: 1.25, F2MinusF1: 1.1},
+}
+
+// WorleyField is cellular noise: one random feature point per lattice
+// cell, with the value at a point given by its distances to the
+// nearest features.
+type WorleyField struct {
+	seed   int64
+	scale  float64
+	value  CellValue
+	metric Metric
+	period int // lattice cells per repeat, 0 = no repeat
+}
+
+// NewWorleyField creates a 2D Worley field.
+//
+// seed  → determinism
+// scale → cell size (smaller = more cells)
+func NewWorleyField(seed int64, scale float64, value CellValue, metric Metric) *WorleyField {
+	return &WorleyField{seed: seed, scale: scale, value: value, metric: metric}
+}
+
+// NewTiledWorleyField creates a Worley field that repeats every 1 unit
+// in x and y, with cells lattice cells per repeat.
+func NewTiledWorleyField(seed int64, cells int, value CellValue, metric Metric) *WorleyField {
+	return &WorleyField{seed: seed, scale: 1 / float64(cells), value: value, metric: metric, period: cells}
+}
+
+// At returns the selected distance at (x,y), mapped from [0,1] cell
+// units to [-1,1].
+func (w *WorleyField) At(x, y float64) float64 {
+	if w.scale <= 0 {
+		return 0
+	}
+	x, y = x/w.scale, y/w.scale
+	ix, iy := int(math.Floor(x)), int(math.Floor(y))
+
+	f1, f2 := math.Inf(1), math.Inf(1)
+	for dy := -1; dy <= 1; dy++ {
+		for dx := -1; dx <= 1; dx++ {
+			cx, cy := ix+dx, iy+dy
+			h := hash(w.seed, wrapCell(cx, w.period), wrapCell(cy, w.period))
+			d := distance(w.metric,
+				float64(cx)+unit(h, 0)-x,
+				float64(cy)+unit(h, 1)-y,
+				0)
+			f1, f2 = nearest(f1, f2, d)
+		}
+	}
+	return cellValue(w.metric, w.value, f1, f2)
+}
+
+// WorleyField3D is WorleyField in three dimensions.
+type WorleyField3D struct {
+	seed   int64
+	scale  float64
+	value  CellValue
+	metric Metric
+}
+
+func NewWorleyField3D(seed int64, scale float64, value CellValue, metric Metric) *WorleyField3D {
+	return &WorleyField3D{seed: seed, scale: scale, value: value, metric: metric}
+}
+
+func (w *WorleyField3D) At(x, y, z float64) float64 {
+	if w.scale <= 0 {
+		return 0
+	}
+	x, y, z = x/w.scale, y/w.scale, z/w.scale
+	ix, iy, iz := int(math.Floor(x)), int(math.Floor(y)), int(math.Floor(z))
+
+	f1, f2 := math.Inf(1), math.Inf(1)
+	for dz := -1; dz <= 1; dz++ {
+		for dy := -1; dy <= 1; dy++ {
+			for dx := -1; dx <= 1; dx++ {
+				cx, cy, cz := ix+dx, iy+dy, iz+dz
+				h := hash(w.seed, cx, cy, cz)
+				d := distance(w.metric,
+					float64(cx)+unit(h, 0)-x,
+					float64(cy)+unit(h, 1)-y,
+					float64(cz)+unit(h, 2)-z)
+				f1, f2 = nearest(f1, f2, d)
+			}
+		}
+	}
+	return cellValue(w.metric, w.value, f1, f2)
+}
+
+func distance(m Metric, dx, dy, dz float64) float64 {
+	dx, dy, dz = math.Abs(dx), math.Abs(dy), math.Abs(dz)
+	switch m {
+	case Manhattan:
+		return dx + dy + dz
+	case Chebyshev:
+		return math.Max(dx, math.Max(dy, dz))
+	default:
+		return math.Sqrt(dx*dx + dy*dy + dz*dz)
+	}
+}
+
+// nearest folds d into the two smallest distances so far.
+func nearest(f1, f2, d float64) (float64, float64) {
+	if d < f1 {
+		return d, f1
+	}
+	return f1, math.Min(f2, d)
+}
+
+func cellValue(m Metric, v CellValue, f1, f2 float64) float64 {
+	d := f1
+	switch v {
+	case F2:
+		d = f2
+	case F2MinusF1:
+		d = f2 - f1
+	default:
+		v = F1
+	}
+	bounds, ok := worleyMax[m]
+	if !ok {
+		bounds = worleyMax[Euclidean]
+	}
+	return 2*math.Min(d/bounds[v], 1) - 1
+}
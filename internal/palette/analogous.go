@@ -5,12 +5,18 @@ import (
 	"math"
 )
 
+// Analogous generates colors from the base hue and its two neighbours
+// 30° either side, rising in lightness and saturation.
 func Analogous(base core.RGBA, n int) []core.RGBA {
+	return analogous(hslCylinder, base, n)
+}
+
+func analogous(sp cylinder, base core.RGBA, n int) []core.RGBA {
 	if n < 3 {
 		n = 3
 	}
 
-	h, s, l := RGBToHSL(base.R, base.G, base.B)
+	h, s, l := sp.split(base)
 	colors := make([]core.RGBA, n)
 
 	hues := []float64{math.Mod(h-1.0/12.0, 1.0), h, math.Mod(h+1.0/12.0, 1.0)}
@@ -23,8 +29,7 @@ func Analogous(base core.RGBA, n int) []core.RGBA {
 		lightness := clamp(l*0.3+float64(i)/float64(n-1)*0.7, 0, 1)
 		saturation := clamp(s*0.5+float64(i)/float64(n-1)*0.5, 0, 1)
 
-		r, g, b := HSLToRGB(hue, saturation, lightness)
-		colors[i] = core.RGBA{R: r, G: g, B: b, A: 1}
+		colors[i] = sp.join(hue, saturation, lightness)
 	}
 
 	return colors
@@ -1,6 +1,72 @@
 package palette
 
-import "math"
+import (
+	"fmt"
+	"math"
+
+	"genart/internal/core"
+)
+
+// cylinder is a hue/saturation/lightness style space with every
+// coordinate in [0,1]. Generators are written against it, so the same
+// recipe runs in HSL or, with perceptually even steps, in OKLCH.
+type cylinder struct {
+	split func(c core.RGBA) (h, s, l float64)
+	join  func(h, s, l float64) core.RGBA
+}
+
+var hslCylinder = cylinder{
+	split: func(c core.RGBA) (h, s, l float64) {
+		return RGBToHSL(c.R, c.G, c.B)
+	},
+	join: func(h, s, l float64) core.RGBA {
+		r, g, b := HSLToRGB(h, s, l)
+		return core.RGBA{R: r, G: g, B: b, A: 1}
+	},
+}
+
+// oklchMaxChroma maps OKLCH chroma onto [0,1]; it is roughly the
+// largest chroma sRGB can show.
+const oklchMaxChroma = 0.32
+
+// oklchCylinder uses OKLCH with chroma relative to oklchMaxChroma.
+// Colors outside sRGB keep their lightness and hue and lose chroma.
+var oklchCylinder = cylinder{
+	split: func(c core.RGBA) (h, s, l float64) {
+		l, ch, hd := c.OKLCH()
+		return hd / 360, clamp(ch/oklchMaxChroma, 0, 1), l
+	},
+	join: func(h, s, l float64) core.RGBA {
+		hd := math.Mod(h, 1) * 360
+		ch := s * oklchMaxChroma
+		if !core.InGamutOKLCH(l, ch, hd) {
+			lo, hi := 0.0, ch
+			for i := 0; i < 30; i++ {
+				mid := (lo + hi) / 2
+				if core.InGamutOKLCH(l, mid, hd) {
+					lo = mid
+				} else {
+					hi = mid
+				}
+			}
+			ch = lo
+		}
+		return core.FromOKLCH(l, ch, hd, 1)
+	},
+}
+
+// cylinderFor returns the generation space for a palette config.
+// Empty means HSL.
+func cylinderFor(space core.ColorSpace) (cylinder, error) {
+	switch space {
+	case "", core.SpaceHSL:
+		return hslCylinder, nil
+	case core.SpaceOKLCH:
+		return oklchCylinder, nil
+	default:
+		return cylinder{}, fmt.Errorf("palette space %q not supported (available: hsl, oklch)", space)
+	}
+}
 
 // clamp keeps a float in [0,1].
 func clamp(v, min, max float64) float64 {
@@ -0,0 +1,20 @@
+package palette
+
+import "context"
+
+// A configured ramp reaches engines through the context passed to
+// Generate, next to the discrete colors, so ramp coloring can sample
+// it directly instead of re-blending the samples.
+
+type rampKey struct{}
+
+// WithRamp returns a context carrying g as the palette ramp.
+func WithRamp(ctx context.Context, g Gradient) context.Context {
+	return context.WithValue(ctx, rampKey{}, g)
+}
+
+// RampFrom returns the palette ramp carried by ctx, or nil.
+func RampFrom(ctx context.Context) Gradient {
+	g, _ := ctx.Value(rampKey{}).(Gradient)
+	return g
+}
@@ -0,0 +1,207 @@
+package palette
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"genart/internal/core"
+)
+
+// Load reads a palette file, picking the format from the extension:
+//
+//	.gpl          GIMP palette
+//	.ase          Adobe Swatch Exchange
+//	.txt, .hex    Paint.NET palette or plain hex colors, one per line
+func Load(path string) ([]core.RGBA, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var colors []core.RGBA
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".gpl":
+		colors, err = parseGPL(bytes.NewReader(data))
+	case ".ase":
+		colors, err = parseASE(data)
+	case ".txt", ".hex":
+		colors, err = parseHexList(bytes.NewReader(data))
+	default:
+		return nil, fmt.Errorf("unknown palette file type %q (available: .gpl, .ase, .txt, .hex)", ext)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if len(colors) == 0 {
+		return nil, fmt.Errorf("%s: no colors", path)
+	}
+	return colors, nil
+}
+
+// parseGPL reads a GIMP palette: a "GIMP Palette" header, optional
+// Name/Columns lines and "#" comments, then "r g b [name]" per line
+// with channels in 0-255.
+func parseGPL(r io.Reader) ([]core.RGBA, error) {
+	sc := bufio.NewScanner(r)
+	if !sc.Scan() || strings.TrimSpace(sc.Text()) != "GIMP Palette" {
+		return nil, fmt.Errorf("missing \"GIMP Palette\" header")
+	}
+
+	colors := make([]core.RGBA, 0)
+	for line := 2; sc.Scan(); line++ {
+		text := strings.TrimSpace(sc.Text())
+		if text == "" || strings.HasPrefix(text, "#") ||
+			strings.HasPrefix(text, "Name:") || strings.HasPrefix(text, "Columns:") {
+			continue
+		}
+		fields := strings.Fields(text)
+		if len(fields) < 3 {
+			return nil, fmt.Errorf("line %d: want \"r g b [name]\"", line)
+		}
+		var rgb [3]float64
+		for i := range rgb {
+			v, err := strconv.Atoi(fields[i])
+			if err != nil || v < 0 || v > 255 {
+				return nil, fmt.Errorf("line %d: invalid channel %q", line, fields[i])
+			}
+			rgb[i] = float64(v) / 255
+		}
+		colors = append(colors, core.RGBA{R: rgb[0], G: rgb[1], B: rgb[2], A: 1})
+	}
+	return colors, sc.Err()
+}
+
+// parseHexList reads one color per line, skipping blank lines and
+// ";" or "//" comments. Lines are "rrggbb" (optionally with "#") or, as
+// Paint.NET writes them, "aarrggbb".
+func parseHexList(r io.Reader) ([]core.RGBA, error) {
+	sc := bufio.NewScanner(r)
+	colors := make([]core.RGBA, 0)
+	for line := 1; sc.Scan(); line++ {
+		text := strings.TrimSpace(sc.Text())
+		if text == "" || strings.HasPrefix(text, ";") || strings.HasPrefix(text, "//") {
+			continue
+		}
+		h := strings.TrimPrefix(text, "#")
+		if len(h) == 8 {
+			h = h[2:] + h[:2] // AARRGGBB → RRGGBBAA
+		}
+		c, err := core.ParseHex(h)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		colors = append(colors, c)
+	}
+	return colors, sc.Err()
+}
+
+// ASE block types.
+const (
+	aseColor      = 0x0001
+	aseGroupStart = 0xc001
+	aseGroupEnd   = 0xc002
+)
+
+// parseASE reads an Adobe Swatch Exchange file. Groups are flattened;
+// RGB, CMYK, LAB and Gray swatches are converted to sRGB.
+func parseASE(data []byte) ([]core.RGBA, error) {
+	if len(data) < 12 || string(data[:4]) != "ASEF" {
+		return nil, fmt.Errorf("not an ASE file")
+	}
+	count := binary.BigEndian.Uint32(data[8:])
+	data = data[12:]
+
+	colors := make([]core.RGBA, 0)
+	for i := uint32(0); i < count; i++ {
+		if len(data) < 6 {
+			return nil, fmt.Errorf("block %d: truncated", i)
+		}
+		typ := binary.BigEndian.Uint16(data)
+		size := binary.BigEndian.Uint32(data[2:])
+		if uint64(len(data)-6) < uint64(size) {
+			return nil, fmt.Errorf("block %d: truncated", i)
+		}
+		block := data[6 : 6+size]
+		data = data[6+size:]
+
+		switch typ {
+		case aseGroupStart, aseGroupEnd:
+			continue
+		case aseColor:
+			c, err := parseASEColor(block)
+			if err != nil {
+				return nil, fmt.Errorf("block %d: %w", i, err)
+			}
+			colors = append(colors, c)
+		default:
+			return nil, fmt.Errorf("block %d: unknown type %#04x", i, typ)
+		}
+	}
+	return colors, nil
+}
+
+// parseASEColor decodes a color block: a UTF-16 name prefixed by its
+// length in code units, a 4-byte model and float32 channel values.
+func parseASEColor(b []byte) (core.RGBA, error) {
+	if len(b) < 2 {
+		return core.RGBA{}, fmt.Errorf("truncated color")
+	}
+	nameLen := int(binary.BigEndian.Uint16(b)) * 2
+	b = b[2:]
+	if len(b) < nameLen+4 {
+		return core.RGBA{}, fmt.Errorf("truncated color")
+	}
+	b = b[nameLen:]
+	model := string(b[:4])
+	b = b[4:]
+
+	values := func(n int) ([]float64, error) {
+		if len(b) < 4*n {
+			return nil, fmt.Errorf("truncated %q values", model)
+		}
+		out := make([]float64, n)
+		for i := range out {
+			out[i] = float64(math.Float32frombits(binary.BigEndian.Uint32(b[4*i:])))
+		}
+		return out, nil
+	}
+
+	switch model {
+	case "RGB ":
+		v, err := values(3)
+		if err != nil {
+			return core.RGBA{}, err
+		}
+		return core.RGBA{R: v[0], G: v[1], B: v[2], A: 1}, nil
+	case "CMYK":
+		v, err := values(4)
+		if err != nil {
+			return core.RGBA{}, err
+		}
+		k := 1 - v[3]
+		return core.RGBA{R: (1 - v[0]) * k, G: (1 - v[1]) * k, B: (1 - v[2]) * k, A: 1}, nil
+	case "LAB ":
+		v, err := values(3)
+		if err != nil {
+			return core.RGBA{}, err
+		}
+		// lightness is stored as a fraction
+		return core.FromLab(v[0]*100, v[1], v[2], 1), nil
+	case "Gray":
+		v, err := values(1)
+		if err != nil {
+			return core.RGBA{}, err
+		}
+		return core.RGBA{R: v[0], G: v[0], B: v[0], A: 1}, nil
+	default:
+		return core.RGBA{}, fmt.Errorf("unknown color model %q", model)
+	}
+}
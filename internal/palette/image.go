@@ -0,0 +1,73 @@
+package palette
+
+import (
+	"fmt"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+	"os"
+	"sort"
+
+	"genart/internal/config"
+	"genart/internal/core"
+	"genart/internal/quantize"
+)
+
+// FromImage extracts pc.N colors from the image at pc.Source using
+// pc.Method ("kmeans" or "median-cut") in pc.Space ("srgb" or "oklab"),
+// ordered by pc.Sort ("lightness", dark to light, or "frequency", most
+// common first). Resolved defaults are written back to pc.
+func FromImage(pc *config.PaletteConfig) ([]core.RGBA, error) {
+	if pc.Source == "" {
+		return nil, fmt.Errorf("image palette needs a source")
+	}
+	if pc.N < 1 {
+		return nil, fmt.Errorf("image palette needs n >= 1, got %d", pc.N)
+	}
+	if pc.Method == "" {
+		pc.Method = quantize.MethodKMeans
+	}
+	if pc.Sort == "" {
+		pc.Sort = "lightness"
+	}
+	if pc.Sort != "lightness" && pc.Sort != "frequency" {
+		return nil, fmt.Errorf("unknown palette sort %q (available: lightness, frequency)", pc.Sort)
+	}
+	space := core.ColorSpace(pc.Space)
+	switch space {
+	case "", core.SpaceSRGB, core.SpaceOKLab:
+	default:
+		return nil, fmt.Errorf("image palette space %q not supported (available: srgb, oklab)", pc.Space)
+	}
+
+	f, err := os.Open(pc.Source)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	img, _, err := image.Decode(f)
+	if err != nil {
+		return nil, fmt.Errorf("decode %s: %w", pc.Source, err)
+	}
+
+	clusters, err := quantize.Extract(img, pc.N, pc.Method, space)
+	if err != nil {
+		return nil, err
+	}
+
+	if pc.Sort == "frequency" {
+		sort.SliceStable(clusters, func(i, j int) bool { return clusters[i].Weight > clusters[j].Weight })
+	} else {
+		sort.SliceStable(clusters, func(i, j int) bool {
+			li, _, _ := clusters[i].Color.OKLab()
+			lj, _, _ := clusters[j].Color.OKLab()
+			return li < lj
+		})
+	}
+
+	colors := make([]core.RGBA, len(clusters))
+	for i, c := range clusters {
+		colors[i] = c.Color
+	}
+	return colors, nil
+}
@@ -4,8 +4,37 @@ import (
 	"math/rand"
 
 	"genart/internal/core"
+	"genart/internal/registry"
 )
 
+func init() {
+	registry.Palettes.Register("mono", generator(monochrome), "monochrome")
+	registry.Palettes.Register("analogous", generator(analogous))
+	registry.Palettes.Register("split-complementary", generator(splitComplementary), "splitcomplementary")
+	registry.Palettes.Register("warm", fixed(Warm))
+	registry.Palettes.Register("cool", fixed(Cool))
+	registry.Palettes.Register("rainbow", fixed(Rainbow))
+}
+
+// generator adapts a cylinder recipe to a registry.PaletteFunc.
+func generator(gen func(sp cylinder, base core.RGBA, n int) []core.RGBA) registry.PaletteFunc {
+	return func(base core.RGBA, n int, space core.ColorSpace) ([]core.RGBA, error) {
+		sp, err := cylinderFor(space)
+		if err != nil {
+			return nil, err
+		}
+		return gen(sp, base, n), nil
+	}
+}
+
+// fixed adapts a predefined palette to a registry.PaletteFunc; base,
+// n and space are ignored.
+func fixed(p Palette) registry.PaletteFunc {
+	return func(core.RGBA, int, core.ColorSpace) ([]core.RGBA, error) {
+		return append([]core.RGBA(nil), p...), nil
+	}
+}
+
 // Palette is just a slice of RGBA colors.
 type Palette []core.RGBA
 
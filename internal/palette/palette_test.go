@@ -1,8 +1,19 @@
 package palette
 
 import (
+	"encoding/binary"
+	"encoding/json"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
 	"math/rand"
+	"os"
+	"path/filepath"
 	"testing"
+
+	"genart/internal/config"
+	"genart/internal/core"
 )
 
 func TestPick(t *testing.T) {
@@ -19,3 +30,244 @@ func TestByIndex(t *testing.T) {
 		t.Fatalf("expected alpha=1, got %f", c.A)
 	}
 }
+
+func TestResolve(t *testing.T) {
+	base := core.RGBA{R: 0.25, G: 0.5, B: 0.25, A: 1}
+	got, err := Resolve(&config.PaletteConfig{Type: "analogous", Base: base, N: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Analogous(base, 5)
+	if len(got) != len(want) || got[1] != want[1] {
+		t.Errorf("Resolve = %v, want %v", got, want)
+	}
+
+	if _, err := Resolve(&config.PaletteConfig{Type: "plaid"}); err == nil {
+		t.Error("expected error for unknown palette type")
+	}
+}
+
+func TestResample(t *testing.T) {
+	in := []core.RGBA{{A: 1}, {R: 1, G: 1, B: 1, A: 1}}
+	out := Resample(in, 3)
+	if out[0] != in[0] || out[2] != in[1] {
+		t.Errorf("ends changed: %v", out)
+	}
+	// the OKLab midpoint of black and white is a neutral grey
+	if m := out[1]; math.Abs(m.R-m.G) > 1e-6 || math.Abs(m.G-m.B) > 1e-6 || m.R <= 0.3 || m.R >= 0.7 {
+		t.Errorf("midpoint = %v", m)
+	}
+}
+
+func TestOKLCHMonochromeEvenLightness(t *testing.T) {
+	colors, err := Resolve(&config.PaletteConfig{Type: "mono", Base: core.RGBA{R: 0.2, G: 0.4, B: 0.7, A: 1}, N: 6, Space: "oklch"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	prev, _, _ := colors[0].OKLCH()
+	step := -1.0
+	for _, c := range colors[1:] {
+		l, _, _ := c.OKLCH()
+		if step < 0 {
+			step = l - prev
+		} else if math.Abs(l-prev-step) > 1e-3 {
+			t.Errorf("uneven lightness steps: %g vs %g", l-prev, step)
+		}
+		prev = l
+	}
+
+	if _, err := Resolve(&config.PaletteConfig{Type: "mono", N: 3, Space: "cmyk"}); err == nil {
+		t.Error("expected error for unsupported space")
+	}
+}
+
+func TestFromImage(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	for i := 0; i < 16; i++ {
+		c := color.RGBA{R: 230, G: 220, B: 210, A: 255}
+		if i < 12 {
+			c = color.RGBA{R: 20, G: 30, B: 90, A: 255}
+		}
+		img.SetRGBA(i%4, i/4, c)
+	}
+	path := filepath.Join(t.TempDir(), "ref.png")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := png.Encode(f, img); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	pc := config.PaletteConfig{Type: "image", Source: path, N: 2, Sort: "lightness"}
+	colors, err := Resolve(&pc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(colors) != 2 || colors[0].B > colors[1].B {
+		t.Fatalf("got %v, want dark blue then light", colors)
+	}
+	if len(pc.Colors) != 2 || pc.Method != "kmeans" {
+		t.Errorf("result not recorded in config: %+v", pc)
+	}
+
+	// recorded colors are reused without the source
+	pc.Source = filepath.Join(t.TempDir(), "missing.png")
+	if _, err := Resolve(&pc); err != nil {
+		t.Errorf("recorded palette needed the source: %v", err)
+	}
+
+	pc = config.PaletteConfig{Type: "image", Source: path, N: 2, Sort: "frequency", Space: "oklab"}
+	colors, err = Resolve(&pc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if colors[0].B > 0.5 {
+		t.Errorf("most frequent color first: got %v", colors)
+	}
+}
+
+func TestLoadFiles(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name string, data []byte) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	// ASE: one RGB swatch named "A" inside a group, plus one gray
+	ase := []byte("ASEF\x00\x01\x00\x00\x00\x00\x00\x04")
+	ase = append(ase, 0xc0, 0x01, 0, 0, 0, 0)
+	rgb := []byte{0, 2, 0, 'A', 0, 0, 'R', 'G', 'B', ' '}
+	for _, v := range []float32{1, 0.5, 0} {
+		rgb = binary.BigEndian.AppendUint32(rgb, math.Float32bits(v))
+	}
+	rgb = append(rgb, 0, 2)
+	ase = append(ase, 0, 1)
+	ase = binary.BigEndian.AppendUint32(ase, uint32(len(rgb)))
+	ase = append(ase, rgb...)
+	gray := []byte{0, 1, 0, 0, 'G', 'r', 'a', 'y'}
+	gray = binary.BigEndian.AppendUint32(gray, math.Float32bits(0.25))
+	gray = append(gray, 0, 2)
+	ase = append(ase, 0, 1)
+	ase = binary.BigEndian.AppendUint32(ase, uint32(len(gray)))
+	ase = append(ase, gray...)
+	ase = append(ase, 0xc0, 0x02, 0, 0, 0, 0)
+
+	want := []core.RGBA{{R: 1, G: 0.5, B: 0, A: 1}, {R: 0.25, G: 0.25, B: 0.25, A: 1}}
+	for _, path := range []string{
+		write("p.gpl", []byte("GIMP Palette\nName: test\nColumns: 2\n# comment\n255 128 0 Orange\n 64  64  64\tGrey\n")),
+		write("p.txt", []byte("; paint.net palette\nFFFF8000\nFF404040\n")),
+		write("p.hex", []byte("#ff8000\n\n404040\n")),
+		write("p.ase", ase),
+	} {
+		colors, err := Load(path)
+		if err != nil {
+			t.Fatalf("%s: %v", filepath.Base(path), err)
+		}
+		if len(colors) != 2 {
+			t.Fatalf("%s: got %d colors, want 2", filepath.Base(path), len(colors))
+		}
+		for i, c := range colors {
+			if math.Abs(c.R-want[i].R) > 0.01 || math.Abs(c.G-want[i].G) > 0.01 || math.Abs(c.B-want[i].B) > 0.01 {
+				t.Errorf("%s: color %d = %v, want %v", filepath.Base(path), i, c, want[i])
+			}
+		}
+	}
+
+	for _, path := range []string{
+		write("bad.gpl", []byte("255 0 0\n")),
+		write("bad.hex", []byte("#12345\n")),
+		write("bad.ase", []byte("ASEX")),
+		write("p.aco", []byte{}),
+	} {
+		if _, err := Load(path); err == nil {
+			t.Errorf("%s: expected error", filepath.Base(path))
+		}
+	}
+}
+
+func TestResolveColorsAndNamed(t *testing.T) {
+	var pc config.PaletteConfig
+	if err := json.Unmarshal([]byte(`{"type":"colors","colors":["#e07a5f",[0,0,1],"#fff8"]}`), &pc); err != nil {
+		t.Fatal(err)
+	}
+	colors, err := Resolve(&pc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(colors) != 3 || colors[0].R != 224.0/255 || colors[1].B != 1 || colors[2].A != 0x88/255.0 {
+		t.Errorf("got %v", colors)
+	}
+
+	colors, err = Resolve(&config.PaletteConfig{Type: "rainbow"})
+	if err != nil || len(colors) != len(Rainbow) {
+		t.Errorf("rainbow: got %v, %v", colors, err)
+	}
+}
+
+func TestColorRamp(t *testing.T) {
+	black, white := core.RGBA{A: 1}, core.RGBA{R: 1, G: 1, B: 1, A: 1}
+	ramp, err := NewRampAt([]core.RGBA{black, white}, []float64{0.5, 1}, core.SpaceSRGB)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := ramp.At(0.25); got != black {
+		t.Errorf("before first stop: got %v", got)
+	}
+	if got := ramp.At(0.75); math.Abs(got.R-0.5) > 1e-9 {
+		t.Errorf("midway: got %v", got)
+	}
+	if _, err := NewRampAt([]core.RGBA{black, white}, []float64{1, 0}, core.SpaceSRGB); err == nil {
+		t.Error("expected error for decreasing positions")
+	}
+
+	pc := config.PaletteConfig{Type: "colors", Colors: []core.RGBA{black, white}, Ramp: &config.RampConfig{Samples: 5}}
+	colors, err := Resolve(&pc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(colors) != 5 || colors[0] != black || colors[4] != white {
+		t.Errorf("ramp samples = %v", colors)
+	}
+	// stops closer together than the samples survive in the ramp itself
+	red := core.RGBA{R: 1, A: 1}
+	pc = config.PaletteConfig{Type: "colors", Colors: []core.RGBA{black, red, black}, Ramp: &config.RampConfig{Positions: []float64{0.49, 0.5, 0.51}}}
+	colors, g, err := ResolveRamp(&pc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := g.At(0.5); got != red {
+		t.Errorf("ramp at stop: got %v, want %v", got, red)
+	}
+	for _, c := range colors {
+		if c == red {
+			t.Errorf("samples unexpectedly hit the narrow stop")
+		}
+	}
+}
+
+func TestCosineGradient(t *testing.T) {
+	// the classic rainbow: a=b=0.5, c=1, d=(0, 1/3, 2/3)
+	g := CosineGradient{A: [3]float64{0.5, 0.5, 0.5}, B: [3]float64{0.5, 0.5, 0.5}, C: [3]float64{1, 1, 1}, D: [3]float64{0, 1.0 / 3, 2.0 / 3}}
+	if c := g.At(0); math.Abs(c.R-1) > 1e-9 || c.G > 0.5 || c.B > 0.5 {
+		t.Errorf("t=0: got %v, want red-ish", c)
+	}
+
+	pc := config.PaletteConfig{Type: "cosine", N: 4, Cosine: &config.CosineConfig{A: g.A, B: g.B, C: g.C, D: g.D}}
+	colors, err := Resolve(&pc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(colors) != 4 || colors[0] != g.At(0) || colors[3] != g.At(1) {
+		t.Errorf("cosine samples = %v", colors)
+	}
+
+	pc.N = 0
+	if _, err := Resolve(&pc); err == nil {
+		t.Error("cosine palette without n: want error")
+	}
+}
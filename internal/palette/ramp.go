@@ -0,0 +1,105 @@
+package palette
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"genart/internal/core"
+)
+
+// Gradient maps t in [0,1] to a color.
+type Gradient interface {
+	At(t float64) core.RGBA
+}
+
+// Stop is one color on a ColorRamp.
+type Stop struct {
+	Pos   float64 // in [0,1]
+	Color core.RGBA
+}
+
+// ColorRamp interpolates between stops in Space. Before the first
+// stop and after the last, the end colors hold.
+type ColorRamp struct {
+	Stops []Stop // sorted by Pos
+	Space core.ColorSpace
+}
+
+// NewRamp spaces colors evenly from 0 to 1.
+func NewRamp(colors []core.RGBA, space core.ColorSpace) ColorRamp {
+	stops := make([]Stop, len(colors))
+	for i, c := range colors {
+		pos := 0.0
+		if len(colors) > 1 {
+			pos = float64(i) / float64(len(colors)-1)
+		}
+		stops[i] = Stop{Pos: pos, Color: c}
+	}
+	return ColorRamp{Stops: stops, Space: space}
+}
+
+// NewRampAt places colors at the given positions, which must be
+// non-decreasing and within [0,1].
+func NewRampAt(colors []core.RGBA, positions []float64, space core.ColorSpace) (ColorRamp, error) {
+	if len(positions) != len(colors) {
+		return ColorRamp{}, fmt.Errorf("ramp: %d positions for %d colors", len(positions), len(colors))
+	}
+	if !sort.Float64sAreSorted(positions) || positions[0] < 0 || positions[len(positions)-1] > 1 {
+		return ColorRamp{}, fmt.Errorf("ramp: positions must increase within [0,1]")
+	}
+	stops := make([]Stop, len(colors))
+	for i, c := range colors {
+		stops[i] = Stop{Pos: positions[i], Color: c}
+	}
+	return ColorRamp{Stops: stops, Space: space}, nil
+}
+
+// At returns the ramp color at t.
+func (r ColorRamp) At(t float64) core.RGBA {
+	s := r.Stops
+	if len(s) == 0 {
+		return core.RGBA{R: 0, G: 0, B: 0, A: 1} // fallback to black
+	}
+	if t <= s[0].Pos {
+		return s[0].Color
+	}
+	for i := 1; i < len(s); i++ {
+		if t <= s[i].Pos {
+			a, b := s[i-1], s[i]
+			if b.Pos == a.Pos {
+				return b.Color
+			}
+			return a.Color.Mix(b.Color, (t-a.Pos)/(b.Pos-a.Pos), r.Space)
+		}
+	}
+	return s[len(s)-1].Color
+}
+
+// CosineGradient is the procedural palette a + b·cos(2π(c·t + d)),
+// evaluated per channel and clipped to [0,1].
+type CosineGradient struct {
+	A, B, C, D [3]float64
+}
+
+// At returns the gradient color at t.
+func (g CosineGradient) At(t float64) core.RGBA {
+	var v [3]float64
+	for i := range v {
+		v[i] = math.Max(0, math.Min(1, g.A[i]+g.B[i]*math.Cos(2*math.Pi*(g.C[i]*t+g.D[i]))))
+	}
+	return core.RGBA{R: v[0], G: v[1], B: v[2], A: 1}
+}
+
+// Sample returns n colors evenly spaced along g, ends included.
+func Sample(g Gradient, n int) []core.RGBA {
+	colors := make([]core.RGBA, n)
+	for i := range colors {
+		t := 0.0
+		if n > 1 {
+			t = float64(i) / float64(n-1)
+		}
+		colors[i] = g.At(t)
+	}
+	return colors
+}
@@ -0,0 +1,116 @@
+package palette
+
+import (
+	"fmt"
+
+	"genart/internal/config"
+	"genart/internal/core"
+	"genart/internal/registry"
+)
+
+// Resolve builds the colors described by pc using the registered
+// palette generators. It is the one place configs turn into palettes,
+// for static runs and for every animation frame alike.
+//
+// Type "colors" uses pc.Colors as given and "cosine" samples pc.N
+// colors from pc.Cosine. Image and file palettes are read once and
+// recorded in pc.Colors, so the dumped config reproduces the run
+// without the source. With pc.Ramp set, the result is resampled along
+// a smooth ramp through those colors.
+func Resolve(pc *config.PaletteConfig) ([]core.RGBA, error) {
+	colors, _, err := ResolveRamp(pc)
+	return colors, err
+}
+
+// ResolveRamp is Resolve for callers that can sample colors
+// continuously: it also returns the ramp configured by pc.Ramp, or nil
+// when there is none. The colors are discrete samples of that ramp.
+func ResolveRamp(pc *config.PaletteConfig) ([]core.RGBA, Gradient, error) {
+	colors, err := baseColors(pc)
+	if err != nil {
+		return nil, nil, err
+	}
+	if pc.Ramp == nil {
+		return colors, nil, nil
+	}
+
+	r := pc.Ramp
+	space, err := core.ParseColorSpace(r.Space, core.SpaceOKLab)
+	if err != nil {
+		return nil, nil, err
+	}
+	ramp := NewRamp(colors, space)
+	if len(r.Positions) > 0 {
+		ramp, err = NewRampAt(colors, r.Positions, space)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	samples := r.Samples
+	if samples <= 0 {
+		samples = 32
+	}
+	return Sample(ramp, samples), ramp, nil
+}
+
+func baseColors(pc *config.PaletteConfig) ([]core.RGBA, error) {
+	switch pc.Type {
+	case "colors":
+		if len(pc.Colors) == 0 {
+			return nil, fmt.Errorf("palette type \"colors\" needs a colors list")
+		}
+		return pc.Colors, nil
+	case "cosine":
+		if pc.Cosine == nil {
+			return nil, fmt.Errorf("palette type \"cosine\" needs a cosine section")
+		}
+		if pc.N < 1 {
+			return nil, fmt.Errorf("cosine palette needs n >= 1, got %d", pc.N)
+		}
+		c := pc.Cosine
+		return Sample(CosineGradient{A: c.A, B: c.B, C: c.C, D: c.D}, pc.N), nil
+	case "image", "file":
+		if len(pc.Colors) == 0 {
+			var colors []core.RGBA
+			var err error
+			if pc.Type == "image" {
+				colors, err = FromImage(pc)
+			} else if pc.Source == "" {
+				err = fmt.Errorf("file palette needs a source")
+			} else {
+				colors, err = Load(pc.Source)
+			}
+			if err != nil {
+				return nil, err
+			}
+			pc.Colors = colors
+		}
+		return pc.Colors, nil
+	}
+
+	gen, err := registry.Palettes.Lookup(pc.Type)
+	if err != nil {
+		return nil, err
+	}
+	return gen(pc.Base, pc.N, core.ColorSpace(pc.Space))
+}
+
+// Resample returns n colors spread evenly along colors, blending
+// neighbours in OKLab. It lets palettes of different sizes be
+// interpolated entry by entry.
+func Resample(colors []core.RGBA, n int) []core.RGBA {
+	out := make([]core.RGBA, n)
+	if len(colors) == 0 {
+		return out
+	}
+	for i := range out {
+		if len(colors) == 1 || n == 1 {
+			out[i] = colors[0]
+			continue
+		}
+		pos := float64(i) / float64(n-1) * float64(len(colors)-1)
+		j := min(int(pos), len(colors)-2)
+		out[i] = colors[j].Mix(colors[j+1], pos-float64(j), core.SpaceOKLab)
+	}
+	return out
+}
@@ -8,11 +8,15 @@ import (
 
 // SplitComplementary generates a vivid split-complementary palette.
 func SplitComplementary(base core.RGBA, n int) []core.RGBA {
+	return splitComplementary(hslCylinder, base, n)
+}
+
+func splitComplementary(sp cylinder, base core.RGBA, n int) []core.RGBA {
 	if n < 3 {
 		n = 3
 	}
 
-	h, s, _ := RGBToHSL(base.R, base.G, base.B)
+	h, s, _ := sp.split(base)
 	colors := make([]core.RGBA, n)
 
 	// main hue + split complementary (±30° from opposite)
@@ -29,8 +33,7 @@ func SplitComplementary(base core.RGBA, n int) []core.RGBA {
 		saturation := clamp(s*0.9+(float64(i)/float64(n-1))*0.1, 0.6, 1.0)
 		lightness := clamp(0.4+(float64(i)/float64(n-1))*0.3, 0, 1)
 
-		r, g, b := HSLToRGB(hue, saturation, lightness)
-		colors[i] = core.RGBA{R: r, G: g, B: b, A: 1}
+		colors[i] = sp.join(hue, saturation, lightness)
 	}
 
 	return colors
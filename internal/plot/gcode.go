@@ -0,0 +1,51 @@
+package plot
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+
+	"genart/internal/config"
+)
+
+// WriteGCode writes doc as G-code in absolute millimetres with the
+// origin at the lower-left corner of the paper. The pen is raised and
+// lowered with opts.PenUp / opts.PenDown, and the program pauses (M0)
+// for a pen change before every layer after the first.
+func WriteGCode(w io.Writer, doc Document, opts config.PlotConfig) error {
+	bw := bufio.NewWriter(w)
+	coords := func(x, y float64) (string, string) {
+		return fmtMM(x), fmtMM(doc.Height - y)
+	}
+
+	fmt.Fprintf(bw, "; genart plot %sx%s mm, %d layers\n", fmtMM(doc.Width), fmtMM(doc.Height), len(doc.Layers))
+	fmt.Fprint(bw, "G21 ; millimetres\n")
+	fmt.Fprint(bw, "G90 ; absolute positioning\n")
+	fmt.Fprintln(bw, opts.PenUp)
+
+	for i, l := range doc.Layers {
+		fmt.Fprintf(bw, "; layer %d rgb(%d,%d,%d)\n", i+1, to8(l.Color.R), to8(l.Color.G), to8(l.Color.B))
+		if i > 0 {
+			fmt.Fprint(bw, "G0 X0 Y0\n")
+			fmt.Fprint(bw, "M0 ; change pen\n")
+		}
+		for _, p := range l.Paths {
+			x, y := coords(p[0].X, p[0].Y)
+			fmt.Fprintf(bw, "G0 X%s Y%s\n", x, y)
+			fmt.Fprintln(bw, opts.PenDown)
+			for k, q := range p[1:] {
+				x, y := coords(q.X, q.Y)
+				if k == 0 {
+					fmt.Fprintf(bw, "G1 X%s Y%s F%s\n", x, y, fmtMM(opts.Feed))
+				} else {
+					fmt.Fprintf(bw, "G1 X%s Y%s\n", x, y)
+				}
+			}
+			fmt.Fprintln(bw, opts.PenUp)
+		}
+	}
+
+	fmt.Fprint(bw, "G0 X0 Y0\n")
+	fmt.Fprint(bw, "M2\n")
+	return bw.Flush()
+}
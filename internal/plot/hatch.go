@@ -0,0 +1,55 @@
+package plot
+
+import (
+	"math"
+	"sort"
+
+	"genart/internal/geom"
+)
+
+// hatch covers a polygon with parallel lines spacing apart at angleDeg,
+// using the even-odd rule. Rows alternate direction so consecutive
+// lines start near where the previous one ended.
+func hatch(poly []geom.Vec2, spacing, angleDeg float64) [][]geom.Vec2 {
+	if len(poly) < 3 || spacing <= 0 {
+		return nil
+	}
+	angle := angleDeg * math.Pi / 180
+
+	// rotate so hatch lines become horizontal scanlines
+	rp := geom.Rotate(poly, -angle)
+	minY, maxY := math.Inf(1), math.Inf(-1)
+	for _, p := range rp {
+		minY, maxY = math.Min(minY, p.Y), math.Max(maxY, p.Y)
+	}
+
+	lines := make([][]geom.Vec2, 0)
+	row := 0
+	for y := minY + spacing/2; y < maxY; y += spacing {
+		xs := make([]float64, 0, 4)
+		for i := range rp {
+			p1, p2 := rp[i], rp[(i+1)%len(rp)]
+			if (p1.Y <= y) != (p2.Y <= y) {
+				xs = append(xs, p1.X+(y-p1.Y)*(p2.X-p1.X)/(p2.Y-p1.Y))
+			}
+		}
+		sort.Float64s(xs)
+
+		rowLines := make([][]geom.Vec2, 0, len(xs)/2)
+		for k := 0; k+1 < len(xs); k += 2 {
+			seg := []geom.Vec2{{X: xs[k], Y: y}, {X: xs[k+1], Y: y}}
+			if row%2 == 1 {
+				seg[0], seg[1] = seg[1], seg[0]
+			}
+			rowLines = append(rowLines, geom.Rotate(seg, angle))
+		}
+		if row%2 == 1 {
+			for a, b := 0, len(rowLines)-1; a < b; a, b = a+1, b-1 {
+				rowLines[a], rowLines[b] = rowLines[b], rowLines[a]
+			}
+		}
+		lines = append(lines, rowLines...)
+		row++
+	}
+	return lines
+}
@@ -0,0 +1,39 @@
+package plot
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+)
+
+// hpglUnits is the number of HPGL plotter units per millimetre.
+const hpglUnits = 40
+
+// WriteHPGL writes doc as HPGL. Each layer selects the next pen
+// (SP1, SP2, ...); the origin is the lower-left corner of the paper.
+func WriteHPGL(w io.Writer, doc Document) error {
+	bw := bufio.NewWriter(w)
+	toPU := func(x, y float64) (int, int) {
+		return int(math.Round(x * hpglUnits)), int(math.Round((doc.Height - y) * hpglUnits))
+	}
+
+	fmt.Fprint(bw, "IN;\n")
+	for i, l := range doc.Layers {
+		fmt.Fprintf(bw, "SP%d;\n", i+1)
+		for _, p := range l.Paths {
+			x, y := toPU(p[0].X, p[0].Y)
+			fmt.Fprintf(bw, "PU%d,%d;PD", x, y)
+			for k, q := range p[1:] {
+				if k > 0 {
+					fmt.Fprint(bw, ",")
+				}
+				x, y := toPU(q.X, q.Y)
+				fmt.Fprintf(bw, "%d,%d", x, y)
+			}
+			fmt.Fprint(bw, ";\n")
+		}
+	}
+	fmt.Fprint(bw, "PU0,0;SP0;\n")
+	return bw.Flush()
+}
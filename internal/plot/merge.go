@@ -0,0 +1,42 @@
+package plot
+
+import (
+	"math"
+
+	"genart/internal/geom"
+)
+
+// merge joins paths whose start lies within tol of an earlier path's end,
+// turning runs of 2-point segments into long polylines. Paths are
+// visited in order, so each particle trail from an engine becomes one
+// polyline.
+func merge(paths [][]geom.Vec2, tol float64) [][]geom.Vec2 {
+	type key struct{ x, y int64 }
+	keyOf := func(p geom.Vec2) key {
+		return key{int64(math.Round(p.X / tol)), int64(math.Round(p.Y / tol))}
+	}
+
+	out := make([][]geom.Vec2, 0)
+	ends := make(map[key][]int) // open path ends → indices into out
+
+	for _, p := range paths {
+		k := keyOf(p[0])
+		if idxs := ends[k]; len(idxs) > 0 {
+			i := idxs[len(idxs)-1]
+			if len(idxs) == 1 {
+				delete(ends, k)
+			} else {
+				ends[k] = idxs[:len(idxs)-1]
+			}
+			out[i] = append(out[i], p[1:]...)
+			nk := keyOf(out[i][len(out[i])-1])
+			ends[nk] = append(ends[nk], i)
+			continue
+		}
+
+		out = append(out, append([]geom.Vec2(nil), p...))
+		nk := keyOf(p[len(p)-1])
+		ends[nk] = append(ends[nk], len(out)-1)
+	}
+	return out
+}
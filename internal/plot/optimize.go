@@ -0,0 +1,198 @@
+package plot
+
+import (
+	"math"
+
+	"genart/internal/geom"
+)
+
+// 2-opt tuning: how far ahead to look for a better reversal, and how
+// many improvement passes to run at most.
+const (
+	twoOptWindow = 32
+	twoOptPasses = 5
+)
+
+// optimize reorders (and may reverse) paths to shorten pen-up travel:
+// a greedy nearest-neighbour tour starting from home, refined by 2-opt.
+// The input is left untouched, and returned as-is if it was already
+// the shorter tour.
+func optimize(home geom.Vec2, paths [][]geom.Vec2) [][]geom.Vec2 {
+	ordered := nearestNeighbour(home, paths)
+	twoOpt(home, ordered)
+	if penUp(home, ordered) >= penUp(home, paths) {
+		return paths
+	}
+	return ordered
+}
+
+// endpoint is one end of a path registered in the spatial grid.
+type endpoint struct {
+	path    int
+	reverse bool // true when this is the path's end point
+}
+
+// nearestNeighbour repeatedly picks the unused path whose start or end
+// is closest to the pen, using a uniform grid over all endpoints.
+func nearestNeighbour(home geom.Vec2, paths [][]geom.Vec2) [][]geom.Vec2 {
+	n := len(paths)
+	if n < 2 {
+		return paths
+	}
+
+	// grid bounds over all endpoints
+	minX, minY := math.Inf(1), math.Inf(1)
+	maxX, maxY := math.Inf(-1), math.Inf(-1)
+	for _, p := range paths {
+		for _, q := range []geom.Vec2{p[0], p[len(p)-1]} {
+			minX, minY = math.Min(minX, q.X), math.Min(minY, q.Y)
+			maxX, maxY = math.Max(maxX, q.X), math.Max(maxY, q.Y)
+		}
+	}
+	size := math.Max(maxX-minX, maxY-minY)
+	side := int(math.Ceil(math.Sqrt(float64(n))))
+	cell := size / float64(side)
+	if cell <= 0 {
+		cell = 1
+	}
+	gx := int((maxX-minX)/cell) + 1
+	gy := int((maxY-minY)/cell) + 1
+
+	cellOf := func(p geom.Vec2) (int, int) {
+		cx := clampInt(int((p.X-minX)/cell), 0, gx-1)
+		cy := clampInt(int((p.Y-minY)/cell), 0, gy-1)
+		return cx, cy
+	}
+
+	grid := make([][]endpoint, gx*gy)
+	for i, p := range paths {
+		cx, cy := cellOf(p[0])
+		grid[cy*gx+cx] = append(grid[cy*gx+cx], endpoint{path: i})
+		cx, cy = cellOf(p[len(p)-1])
+		grid[cy*gx+cx] = append(grid[cy*gx+cx], endpoint{path: i, reverse: true})
+	}
+
+	used := make([]bool, n)
+	out := make([][]geom.Vec2, 0, n)
+	cur := home
+	maxR := max(gx, gy)
+
+	for len(out) < n {
+		cx, cy := cellOf(cur)
+		best := endpoint{path: -1}
+		bestD := math.Inf(1)
+
+		for r := 0; r <= maxR; r++ {
+			for y := cy - r; y <= cy+r; y++ {
+				if y < 0 || y >= gy {
+					continue
+				}
+				for x := cx - r; x <= cx+r; x++ {
+					if x < 0 || x >= gx {
+						continue
+					}
+					// only the ring at Chebyshev distance r
+					if y != cy-r && y != cy+r && x != cx-r && x != cx+r {
+						continue
+					}
+					c := &grid[y*gx+x]
+					live := (*c)[:0]
+					for _, e := range *c {
+						if used[e.path] {
+							continue
+						}
+						live = append(live, e)
+						q := paths[e.path][0]
+						if e.reverse {
+							q = paths[e.path][len(paths[e.path])-1]
+						}
+						if d := cur.Distance(q); d < bestD {
+							best, bestD = e, d
+						}
+					}
+					*c = live
+				}
+			}
+			// nothing in ring r+1 can beat a hit closer than r cells
+			if best.path >= 0 && bestD <= float64(r)*cell {
+				break
+			}
+		}
+
+		p := paths[best.path]
+		if best.reverse {
+			p = reversed(p)
+		}
+		used[best.path] = true
+		out = append(out, p)
+		cur = p[len(p)-1]
+	}
+	return out
+}
+
+// twoOpt improves a tour by reversing runs of paths whenever the two
+// new connecting moves are shorter than the two they replace. Reversing
+// a run flips both its order and each path's direction, so travel
+// inside the run is unchanged.
+func twoOpt(home geom.Vec2, p [][]geom.Vec2) {
+	n := len(p)
+	end := func(k int) geom.Vec2 {
+		if k < 0 {
+			return home
+		}
+		return p[k][len(p[k])-1]
+	}
+	start := func(k int) geom.Vec2 {
+		if k >= n {
+			return home
+		}
+		return p[k][0]
+	}
+
+	for pass := 0; pass < twoOptPasses; pass++ {
+		improved := false
+		for i := -1; i < n-1; i++ {
+			for j := i + 1; j < n && j <= i+twoOptWindow; j++ {
+				a, b := end(i), start(i+1)
+				c, d := end(j), start(j+1)
+				delta := a.Distance(c) + b.Distance(d) - a.Distance(b) - c.Distance(d)
+				if delta < -1e-9 {
+					reverseRun(p, i+1, j)
+					improved = true
+				}
+			}
+		}
+		if !improved {
+			break
+		}
+	}
+}
+
+// reverseRun reverses p[i..j] and the direction of every path in it.
+func reverseRun(p [][]geom.Vec2, i, j int) {
+	for a, b := i, j; a < b; a, b = a+1, b-1 {
+		p[a], p[b] = p[b], p[a]
+	}
+	for k := i; k <= j; k++ {
+		p[k] = reversed(p[k])
+	}
+}
+
+// reversed returns a reversed copy of p.
+func reversed(p []geom.Vec2) []geom.Vec2 {
+	out := make([]geom.Vec2, len(p))
+	for i, q := range p {
+		out[len(p)-1-i] = q
+	}
+	return out
+}
+
+func clampInt(v, lo, hi int) int {
+	if v < lo {
+		return lo
+	}
+	if v > hi {
+		return hi
+	}
+	return v
+}
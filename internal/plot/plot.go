@@ -0,0 +1,281 @@
+package plot
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"genart/internal/config"
+	"genart/internal/core"
+	"genart/internal/geom"
+)
+
+// Paper sizes in millimetres (portrait).
+var papers = map[string][2]float64{
+	"a5":     {148, 210},
+	"a4":     {210, 297},
+	"a3":     {297, 420},
+	"letter": {215.9, 279.4},
+}
+
+// Layer is a group of polylines drawn with one pen.
+type Layer struct {
+	Color core.RGBA
+	Paths [][]geom.Vec2 // in millimetres
+}
+
+// Document is a Scene converted to physical plotter paths.
+type Document struct {
+	Width, Height float64 // paper size in millimetres
+	PenWidth      float64
+	Layers        []Layer
+}
+
+// Stats reports plot size and travel distances in millimetres.
+type Stats struct {
+	Strokes     int     // input strokes (plus hatch lines)
+	Paths       int     // polylines after merging
+	PenDown     float64 // drawing distance
+	PenUpBefore float64 // travel in Scene order, before merging and optimization
+	PenUpAfter  float64 // travel after merging and optimization
+}
+
+func (s Stats) String() string {
+	return fmt.Sprintf("%d strokes → %d paths, pen-down %.1f mm, pen-up %.1f mm → %.1f mm",
+		s.Strokes, s.Paths, s.PenDown, s.PenUpBefore, s.PenUpAfter)
+}
+
+// Export converts scene into plotter paths according to cfg.Plot and
+// writes them to w in the configured format. Resolved defaults are
+// written back to cfg.Plot so the dumped config is complete.
+func Export(w io.Writer, scene core.Scene, cfg *config.Config) (Stats, error) {
+	if cfg.Plot == nil {
+		return Stats{}, fmt.Errorf("no plot section in config")
+	}
+	opts := cfg.Plot
+	if err := resolve(opts, cfg.Out); err != nil {
+		return Stats{}, err
+	}
+
+	doc, stats, err := Build(scene, float64(cfg.Width)/float64(cfg.Height), *opts)
+	if err != nil {
+		return Stats{}, err
+	}
+
+	switch opts.Format {
+	case "svg":
+		err = WriteSVG(w, doc)
+	case "hpgl":
+		err = WriteHPGL(w, doc)
+	case "gcode":
+		err = WriteGCode(w, doc, *opts)
+	}
+	return stats, err
+}
+
+// Build maps scene onto the paper described by opts, preserving the
+// given width/height aspect, and returns per-color layers of merged and
+// ordered polylines. opts must already have defaults resolved.
+func Build(scene core.Scene, aspect float64, opts config.PlotConfig) (Document, Stats, error) {
+	if aspect <= 0 {
+		return Document{}, Stats{}, fmt.Errorf("plot: invalid aspect ratio %f", aspect)
+	}
+	doc := Document{
+		Width:    opts.PaperWidth,
+		Height:   opts.PaperHeight,
+		PenWidth: opts.PenWidth,
+	}
+
+	// drawable area, fitted to the scene aspect and centered
+	aw := opts.PaperWidth - 2*opts.Margin
+	ah := opts.PaperHeight - 2*opts.Margin
+	if aw <= 0 || ah <= 0 {
+		return Document{}, Stats{}, fmt.Errorf("plot: margin %.1f mm leaves no drawable area", opts.Margin)
+	}
+	dw, dh := aw, aw/aspect
+	if dh > ah {
+		dw, dh = ah*aspect, ah
+	}
+	x0 := (opts.PaperWidth - dw) / 2
+	y0 := (opts.PaperHeight - dh) / 2
+	mapPt := func(v core.Vec2) geom.Vec2 {
+		return geom.Vec2{X: x0 + v.X*dw, Y: y0 + v.Y*dh}
+	}
+
+	stats := Stats{}
+	layers := make([]*segmentLayer, 0)
+	byColor := make(map[core.RGBA]*segmentLayer)
+	layerFor := func(c core.RGBA) *segmentLayer {
+		c.A = 1 // opacity has no meaning for a pen
+		l, ok := byColor[c]
+		if !ok {
+			l = &segmentLayer{color: c}
+			byColor[c] = l
+			layers = append(layers, l)
+		}
+		return l
+	}
+
+	for _, it := range scene.Items {
+		switch s := it.(type) {
+		case core.Stroke:
+			pts := make([]geom.Vec2, 0, len(s.Path.Points)+1)
+			for _, p := range s.Path.Points {
+				pts = append(pts, mapPt(p))
+			}
+			if s.Path.Closed && len(pts) > 2 {
+				pts = append(pts, pts[0])
+			}
+			if len(pts) < 2 {
+				continue
+			}
+			layerFor(s.Color).add(pts)
+			stats.Strokes++
+
+		case core.Fill:
+			if opts.Fills != "hatch" {
+				continue
+			}
+			poly := make([]geom.Vec2, 0, len(s.Polygon.Points))
+			for _, p := range s.Polygon.Points {
+				poly = append(poly, mapPt(p))
+			}
+			l := layerFor(s.Color)
+			for _, line := range hatch(poly, opts.HatchSpacing, opts.HatchAngle) {
+				l.add(line)
+				stats.Strokes++
+			}
+		}
+	}
+
+	// plotters home at the top-left (SVG) or lower-left (HPGL, G-code) corner
+	home := geom.Vec2{}
+	if opts.Format != "svg" {
+		home.Y = opts.PaperHeight
+	}
+	for _, l := range layers {
+		stats.PenDown += penDown(l.paths)
+		stats.PenUpBefore += penUp(home, l.paths)
+
+		paths := merge(l.paths, opts.Tolerance)
+		if *opts.Optimize {
+			paths = optimize(home, paths)
+		}
+		stats.Paths += len(paths)
+		stats.PenUpAfter += penUp(home, paths)
+
+		doc.Layers = append(doc.Layers, Layer{Color: l.color, Paths: paths})
+	}
+
+	return doc, stats, nil
+}
+
+// segmentLayer collects raw paths for one color in Scene order.
+type segmentLayer struct {
+	color core.RGBA
+	paths [][]geom.Vec2
+}
+
+func (l *segmentLayer) add(pts []geom.Vec2) {
+	l.paths = append(l.paths, pts)
+}
+
+// resolve fills in defaults and validates opts in place.
+func resolve(opts *config.PlotConfig, out string) error {
+	if opts.Format == "" {
+		switch strings.ToLower(filepath.Ext(out)) {
+		case ".hpgl", ".plt":
+			opts.Format = "hpgl"
+		case ".gcode", ".nc", ".gc":
+			opts.Format = "gcode"
+		default:
+			opts.Format = "svg"
+		}
+	}
+	switch opts.Format {
+	case "svg", "hpgl", "gcode":
+	default:
+		return fmt.Errorf("plot: unknown format %q", opts.Format)
+	}
+
+	if opts.PaperWidth <= 0 || opts.PaperHeight <= 0 {
+		if opts.Paper == "" {
+			opts.Paper = "A4"
+		}
+		size, ok := papers[strings.ToLower(opts.Paper)]
+		if !ok {
+			return fmt.Errorf("plot: unknown paper %q", opts.Paper)
+		}
+		opts.PaperWidth, opts.PaperHeight = size[0], size[1]
+	}
+	// landscape puts the long side across, whether the size is named or
+	// explicit; a resolved config dumped and run again keeps its size
+	if opts.Landscape && opts.PaperWidth < opts.PaperHeight {
+		opts.PaperWidth, opts.PaperHeight = opts.PaperHeight, opts.PaperWidth
+	}
+
+	if opts.Margin < 0 {
+		return fmt.Errorf("plot: invalid margin %.1f (must be >= 0)", opts.Margin)
+	}
+	if opts.PenWidth <= 0 {
+		opts.PenWidth = 0.3
+	}
+
+	switch opts.Fills {
+	case "":
+		opts.Fills = "drop"
+	case "drop", "hatch":
+	default:
+		return fmt.Errorf("plot: unknown fills mode %q", opts.Fills)
+	}
+	if opts.HatchSpacing <= 0 {
+		opts.HatchSpacing = opts.PenWidth
+	}
+
+	if opts.Tolerance <= 0 {
+		opts.Tolerance = 0.01
+	}
+	if opts.Optimize == nil {
+		optimize := true
+		opts.Optimize = &optimize
+	}
+
+	if opts.Format == "gcode" {
+		if opts.Feed <= 0 {
+			opts.Feed = 3000
+		}
+		if opts.PenUp == "" {
+			opts.PenUp = "G0 Z5"
+		}
+		if opts.PenDown == "" {
+			opts.PenDown = "G1 Z0 F1000"
+		}
+	}
+	return nil
+}
+
+// penDown sums the drawn length of paths.
+func penDown(paths [][]geom.Vec2) float64 {
+	d := 0.0
+	for _, p := range paths {
+		for i := 1; i < len(p); i++ {
+			d += p[i-1].Distance(p[i])
+		}
+	}
+	return d
+}
+
+// penUp sums travel between consecutive paths, starting and ending at home.
+func penUp(home geom.Vec2, paths [][]geom.Vec2) float64 {
+	if len(paths) == 0 {
+		return 0
+	}
+	d := 0.0
+	cur := home
+	for _, p := range paths {
+		d += cur.Distance(p[0])
+		cur = p[len(p)-1]
+	}
+	return d + cur.Distance(home)
+}
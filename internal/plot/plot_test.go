@@ -0,0 +1,121 @@
+package plot
+
+import (
+	"bytes"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"genart/internal/config"
+	"genart/internal/core"
+	"genart/internal/geom"
+)
+
+func TestMergeChainsSegments(t *testing.T) {
+	paths := [][]geom.Vec2{
+		{{X: 0, Y: 0}, {X: 1, Y: 0}},
+		{{X: 5, Y: 5}, {X: 6, Y: 5}},
+		{{X: 1, Y: 0}, {X: 2, Y: 0}},
+		{{X: 2, Y: 0}, {X: 3, Y: 1}},
+	}
+	out := merge(paths, 0.01)
+	if len(out) != 2 {
+		t.Fatalf("expected 2 polylines, got %d", len(out))
+	}
+	if len(out[0]) != 4 {
+		t.Fatalf("expected 4 points in first polyline, got %d", len(out[0]))
+	}
+}
+
+func TestOptimizeReducesTravel(t *testing.T) {
+	rng := rand.New(rand.NewSource(42))
+	paths := make([][]geom.Vec2, 0, 500)
+	for i := 0; i < 500; i++ {
+		p := geom.Vec2{X: rng.Float64() * 200, Y: rng.Float64() * 200}
+		paths = append(paths, []geom.Vec2{p, p.Add(geom.Vec2{X: 1, Y: 1})})
+	}
+
+	home := geom.Vec2{}
+	before := penUp(home, paths)
+	ordered := optimize(home, paths)
+	after := penUp(home, ordered)
+
+	if len(ordered) != len(paths) {
+		t.Fatalf("expected %d paths, got %d", len(paths), len(ordered))
+	}
+	if after >= before/2 {
+		t.Errorf("expected travel to drop substantially: %.1f → %.1f", before, after)
+	}
+	if !almostEqual(penDown(ordered), penDown(paths)) {
+		t.Errorf("pen-down distance changed")
+	}
+}
+
+func TestHatchSquare(t *testing.T) {
+	square := []geom.Vec2{{X: 0, Y: 0}, {X: 10, Y: 0}, {X: 10, Y: 10}, {X: 0, Y: 10}}
+	lines := hatch(square, 1, 0)
+	if len(lines) != 10 {
+		t.Fatalf("expected 10 hatch lines, got %d", len(lines))
+	}
+	for _, l := range lines {
+		if !almostEqual(l[0].Distance(l[1]), 10) {
+			t.Fatalf("hatch line should span the square, got %v", l)
+		}
+	}
+}
+
+func TestExportFormats(t *testing.T) {
+	scene := core.Scene{}
+	scene.AddStroke([]core.Vec2{{X: 0, Y: 0}, {X: 1, Y: 1}}, false, 0.001, core.RGBA{R: 1, A: 1}, 1)
+	scene.AddFill([]core.Vec2{{X: 0, Y: 0}, {X: 1, Y: 0}, {X: 1, Y: 1}}, core.RGBA{B: 1, A: 1}, 1)
+
+	cases := map[string]string{
+		"out.svg":   "inkscape:groupmode=\"layer\"",
+		"out.hpgl":  "SP1;",
+		"out.gcode": "G21",
+	}
+	for out, want := range cases {
+		cfg := &config.Config{Width: 100, Height: 100, Out: out, Plot: &config.PlotConfig{}}
+		var buf bytes.Buffer
+		stats, err := Export(&buf, scene, cfg)
+		if err != nil {
+			t.Fatalf("%s: export failed: %v", out, err)
+		}
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("%s: expected output to contain %q", out, want)
+		}
+		// fills are dropped by default
+		if stats.Strokes != 1 {
+			t.Errorf("%s: expected 1 stroke, got %d", out, stats.Strokes)
+		}
+	}
+}
+
+func TestLandscape(t *testing.T) {
+	cases := []struct {
+		opts config.PlotConfig
+		w, h float64
+	}{
+		{config.PlotConfig{Paper: "A4", Landscape: true}, 297, 210},
+		{config.PlotConfig{PaperWidth: 100, PaperHeight: 200, Landscape: true}, 200, 100},
+		{config.PlotConfig{PaperWidth: 200, PaperHeight: 100, Landscape: true}, 200, 100},
+		{config.PlotConfig{PaperWidth: 100, PaperHeight: 200}, 100, 200},
+	}
+	for _, c := range cases {
+		opts := c.opts
+		// resolving twice, as a dumped config run again would, keeps the size
+		for range 2 {
+			if err := resolve(&opts, "out.svg"); err != nil {
+				t.Fatal(err)
+			}
+			if opts.PaperWidth != c.w || opts.PaperHeight != c.h {
+				t.Errorf("%+v: got %gx%g, want %gx%g", c.opts, opts.PaperWidth, opts.PaperHeight, c.w, c.h)
+			}
+		}
+	}
+}
+
+func almostEqual(a, b float64) bool {
+	d := a - b
+	return d < 1e-6 && d > -1e-6
+}
@@ -0,0 +1,62 @@
+package plot
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+
+	"genart/internal/geom"
+)
+
+// WriteSVG writes doc as an SVG sized in millimetres with one Inkscape
+// layer per pen, the layout AxiDraw-style plotter software expects.
+func WriteSVG(w io.Writer, doc Document) error {
+	bw := bufio.NewWriter(w)
+
+	fmt.Fprintf(bw, `<?xml version="1.0" encoding="UTF-8"?>`+"\n")
+	fmt.Fprintf(bw, `<svg xmlns="http://www.w3.org/2000/svg" xmlns:inkscape="http://www.inkscape.org/namespaces/inkscape" width="%smm" height="%smm" viewBox="0 0 %s %s">`+"\n",
+		fmtMM(doc.Width), fmtMM(doc.Height), fmtMM(doc.Width), fmtMM(doc.Height))
+
+	for i, l := range doc.Layers {
+		color := fmt.Sprintf("rgb(%d,%d,%d)", to8(l.Color.R), to8(l.Color.G), to8(l.Color.B))
+		fmt.Fprintf(bw, `<g inkscape:groupmode="layer" inkscape:label="%d %s" id="layer%d" fill="none" stroke="%s" stroke-width="%s" stroke-linecap="round" stroke-linejoin="round">`+"\n",
+			i+1, color, i+1, color, fmtMM(doc.PenWidth))
+		for _, p := range l.Paths {
+			fmt.Fprintf(bw, `<polyline points="%s"/>`+"\n", svgPoints(p))
+		}
+		fmt.Fprintln(bw, "</g>")
+	}
+
+	fmt.Fprintln(bw, "</svg>")
+	return bw.Flush()
+}
+
+func svgPoints(p []geom.Vec2) string {
+	buf := make([]byte, 0, len(p)*16)
+	for i, q := range p {
+		if i > 0 {
+			buf = append(buf, ' ')
+		}
+		buf = strconv.AppendFloat(buf, round3(q.X), 'f', -1, 64)
+		buf = append(buf, ',')
+		buf = strconv.AppendFloat(buf, round3(q.Y), 'f', -1, 64)
+	}
+	return string(buf)
+}
+
+// --- helpers ---
+
+func to8(v float64) int {
+	return int(math.Round(math.Max(0, math.Min(1, v)) * 255))
+}
+
+// round3 keeps micrometre precision, well below any pen's accuracy.
+func round3(v float64) float64 {
+	return math.Round(v*1000) / 1000
+}
+
+func fmtMM(v float64) string {
+	return strconv.FormatFloat(round3(v), 'f', -1, 64)
+}
@@ -0,0 +1,80 @@
+package quantize
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+)
+
+// Dithering modes.
+const (
+	DitherNone           = "none"
+	DitherFloydSteinberg = "floyd-steinberg"
+	DitherBayer          = "bayer"
+)
+
+// bayer8 is the 8×8 ordered-dither threshold matrix.
+var bayer8 = [8][8]float64{
+	{0, 32, 8, 40, 2, 34, 10, 42},
+	{48, 16, 56, 24, 50, 18, 58, 26},
+	{12, 44, 4, 36, 14, 46, 6, 38},
+	{60, 28, 52, 20, 62, 30, 54, 22},
+	{3, 35, 11, 43, 1, 33, 9, 41},
+	{51, 19, 59, 27, 49, 17, 57, 25},
+	{15, 47, 7, 39, 13, 45, 5, 37},
+	{63, 31, 55, 23, 61, 29, 53, 21},
+}
+
+// Draw converts src into dst (whose palette is already set) using the
+// named dithering mode. An empty mode selects Floyd–Steinberg.
+func Draw(dst *image.Paletted, src image.Image, mode string) error {
+	b := src.Bounds()
+	switch mode {
+	case "", DitherFloydSteinberg:
+		draw.FloydSteinberg.Draw(dst, b, src, b.Min)
+	case DitherNone:
+		draw.Draw(dst, b, src, b.Min, draw.Src)
+	case DitherBayer:
+		drawBayer(dst, src)
+	default:
+		return fmt.Errorf("quantize: unknown dither mode %q", mode)
+	}
+	return nil
+}
+
+// drawBayer applies ordered dithering: each pixel is nudged by a
+// position-dependent threshold before picking the nearest color.
+// The nudge is sized to the typical spacing between palette colors.
+func drawBayer(dst *image.Paletted, src image.Image) {
+	spread := 255 / math.Cbrt(float64(len(dst.Palette)))
+	b := src.Bounds()
+	cache := make(map[color.RGBA]uint8)
+
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			c := color.RGBAModel.Convert(src.At(x, y)).(color.RGBA)
+			d := (bayer8[y&7][x&7]/64 - 0.5) * spread
+			c = color.RGBA{R: nudge(c.R, d), G: nudge(c.G, d), B: nudge(c.B, d), A: c.A}
+
+			idx, ok := cache[c]
+			if !ok {
+				idx = uint8(dst.Palette.Index(c))
+				cache[c] = idx
+			}
+			dst.SetColorIndex(x, y, idx)
+		}
+	}
+}
+
+func nudge(v uint8, d float64) uint8 {
+	f := float64(v) + d
+	if f < 0 {
+		return 0
+	}
+	if f > 255 {
+		return 255
+	}
+	return uint8(f + 0.5)
+}
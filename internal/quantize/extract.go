@@ -0,0 +1,137 @@
+package quantize
+
+import (
+	"fmt"
+	"image"
+	"math"
+
+	"genart/internal/core"
+)
+
+// Extraction methods.
+const (
+	MethodKMeans    = "kmeans"
+	MethodMedianCut = "median-cut"
+)
+
+// kmeansIterations bounds Lloyd's algorithm; it usually settles sooner.
+const kmeansIterations = 30
+
+// Cluster is one extracted color and the share of pixels it stands for.
+type Cluster struct {
+	Color  core.RGBA
+	Weight float64 // fraction of the image's opaque pixels
+}
+
+// Extract finds up to n representative colors of img. Median-cut works
+// on the sRGB histogram; k-means starts from the median-cut result and
+// refines it in space (sRGB when empty), e.g. OKLab for clusters that
+// match perceived differences. Fully transparent pixels are ignored.
+func Extract(img image.Image, n int, method string, space core.ColorSpace) ([]Cluster, error) {
+	if n < 1 {
+		return nil, fmt.Errorf("quantize: need at least 1 color, got %d", n)
+	}
+	bins := histogram([]image.Image{img}, true)
+	if len(bins) == 0 {
+		return nil, fmt.Errorf("quantize: image has no opaque pixels")
+	}
+	all := float64(total(bins))
+
+	boxes := split(bins, n)
+	clusters := make([]Cluster, len(boxes))
+	for i, b := range boxes {
+		c := mean(b)
+		clusters[i] = Cluster{
+			Color:  core.RGBA{R: float64(c.R) / 255, G: float64(c.G) / 255, B: float64(c.B) / 255, A: 1},
+			Weight: float64(total(b)) / all,
+		}
+	}
+
+	switch method {
+	case "", MethodKMeans:
+		return kmeans(bins, clusters, space), nil
+	case MethodMedianCut:
+		return clusters, nil
+	default:
+		return nil, fmt.Errorf("quantize: unknown method %q", method)
+	}
+}
+
+// kmeans refines the initial clusters with Lloyd's algorithm over the
+// histogram cells, each weighted by its pixel count. Clusters that end
+// up empty are dropped.
+func kmeans(bins []bin, init []Cluster, space core.ColorSpace) []Cluster {
+	points := make([][3]float64, len(bins))
+	for i, b := range bins {
+		c := core.RGBA{
+			R: float64(b.sumR) / float64(b.count) / 255,
+			G: float64(b.sumG) / float64(b.count) / 255,
+			B: float64(b.sumB) / float64(b.count) / 255,
+			A: 1,
+		}
+		points[i] = c.In(space)
+	}
+	centers := make([][3]float64, len(init))
+	for i, c := range init {
+		centers[i] = c.Color.In(space)
+	}
+
+	assign := make([]int, len(points))
+	weights := make([]float64, len(centers))
+	for iter := 0; iter < kmeansIterations; iter++ {
+		changed := iter == 0
+		for i, p := range points {
+			best, bestD := 0, math.Inf(1)
+			for j, c := range centers {
+				if d := dist2(p, c); d < bestD {
+					best, bestD = j, d
+				}
+			}
+			if assign[i] != best {
+				assign[i] = best
+				changed = true
+			}
+		}
+		if !changed {
+			break
+		}
+
+		sums := make([][3]float64, len(centers))
+		for j := range weights {
+			weights[j] = 0
+		}
+		for i, p := range points {
+			w := float64(bins[i].count)
+			j := assign[i]
+			for k := range p {
+				sums[j][k] += p[k] * w
+			}
+			weights[j] += w
+		}
+		for j := range centers {
+			if weights[j] > 0 {
+				for k := range centers[j] {
+					centers[j][k] = sums[j][k] / weights[j]
+				}
+			}
+		}
+	}
+
+	all := 0.0
+	for _, w := range weights {
+		all += w
+	}
+	out := make([]Cluster, 0, len(centers))
+	for j, c := range centers {
+		if weights[j] == 0 {
+			continue
+		}
+		out = append(out, Cluster{Color: core.FromSpace(space, c, 1), Weight: weights[j] / all})
+	}
+	return out
+}
+
+func dist2(a, b [3]float64) float64 {
+	dx, dy, dz := a[0]-b[0], a[1]-b[1], a[2]-b[2]
+	return dx*dx + dy*dy + dz*dz
+}
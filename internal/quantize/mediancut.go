@@ -0,0 +1,196 @@
+package quantize
+
+import (
+	"image"
+	"image/color"
+	"sort"
+
+	"genart/internal/core"
+)
+
+// histBits is the per-channel precision of the color histogram.
+const histBits = 5
+
+// bin accumulates every pixel that falls into one histogram cell.
+type bin struct {
+	key              [3]uint8 // cell coordinates, histBits per channel
+	count            int
+	sumR, sumG, sumB int
+}
+
+// MedianCut builds a palette of at most n colors that represents the
+// pixels of imgs. seeds (e.g. the configured palette and background)
+// come first and count toward n, but take at most half of it: the
+// remaining slots are filled by recursively splitting the color
+// histogram at the median, so antialiased and blended colors survive
+// however many seeds there are. Seeds past the cap are left to the
+// histogram.
+func MedianCut(imgs []image.Image, n int, seeds []core.RGBA) color.Palette {
+	pal := make(color.Palette, 0, n)
+	seen := make(map[color.RGBA]bool)
+	for _, s := range seeds {
+		if len(pal) >= n/2 {
+			break
+		}
+		c := ToColor(s)
+		if !seen[c] {
+			seen[c] = true
+			pal = append(pal, c)
+		}
+	}
+
+	boxes := split(histogram(imgs, false), n-len(pal))
+	for _, b := range boxes {
+		c := mean(b)
+		if !seen[c] {
+			seen[c] = true
+			pal = append(pal, c)
+		}
+	}
+
+	// GIF needs at least one color
+	if len(pal) == 0 {
+		pal = append(pal, color.RGBA{A: 255})
+	}
+	return pal
+}
+
+// ToColor converts a float color to an opaque 8-bit color.
+func ToColor(c core.RGBA) color.RGBA {
+	to8 := func(v float64) uint8 {
+		if v <= 0 {
+			return 0
+		}
+		if v >= 1 {
+			return 255
+		}
+		return uint8(v*255 + 0.5)
+	}
+	return color.RGBA{R: to8(c.R), G: to8(c.G), B: to8(c.B), A: 255}
+}
+
+// histogram counts pixels of all images in histBits-per-channel cells.
+// With opaqueOnly, fully transparent pixels are left out.
+func histogram(imgs []image.Image, opaqueOnly bool) []bin {
+	const size = 1 << histBits
+	const shift = 8 - histBits
+	cells := make([]bin, size*size*size)
+
+	add := func(r, g, b, a uint8) {
+		if opaqueOnly && a == 0 {
+			return
+		}
+		i := (int(r>>shift)*size+int(g>>shift))*size + int(b>>shift)
+		c := &cells[i]
+		c.count++
+		c.sumR += int(r)
+		c.sumG += int(g)
+		c.sumB += int(b)
+	}
+
+	for _, img := range imgs {
+		bounds := img.Bounds()
+		if rgba, ok := img.(*image.RGBA); ok {
+			for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+				row := rgba.Pix[rgba.PixOffset(bounds.Min.X, y):]
+				for x := 0; x < bounds.Dx(); x++ {
+					add(row[x*4], row[x*4+1], row[x*4+2], row[x*4+3])
+				}
+			}
+			continue
+		}
+		for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+			for x := bounds.Min.X; x < bounds.Max.X; x++ {
+				c := color.RGBAModel.Convert(img.At(x, y)).(color.RGBA)
+				add(c.R, c.G, c.B, c.A)
+			}
+		}
+	}
+
+	out := make([]bin, 0)
+	for i, c := range cells {
+		if c.count == 0 {
+			continue
+		}
+		c.key = [3]uint8{uint8(i / (size * size)), uint8(i / size % size), uint8(i % size)}
+		out = append(out, c)
+	}
+	return out
+}
+
+// split divides bins into at most k boxes, always cutting the box with
+// the largest (pixel count × channel extent) along its longest channel.
+func split(bins []bin, k int) [][]bin {
+	if k <= 0 || len(bins) == 0 {
+		return nil
+	}
+	boxes := [][]bin{bins}
+
+	for len(boxes) < k {
+		best, bestScore, bestAxis := -1, 0, 0
+		for i, b := range boxes {
+			if len(b) < 2 {
+				continue
+			}
+			axis, extent := longestAxis(b)
+			score := extent * total(b)
+			if score > bestScore {
+				best, bestScore, bestAxis = i, score, axis
+			}
+		}
+		if best < 0 {
+			break // every box is a single cell
+		}
+
+		b := boxes[best]
+		sort.Slice(b, func(i, j int) bool { return b[i].key[bestAxis] < b[j].key[bestAxis] })
+
+		// weighted median, keeping both halves non-empty
+		half := total(b) / 2
+		cut, acc := 1, 0
+		for i := 0; i < len(b)-1; i++ {
+			acc += b[i].count
+			cut = i + 1
+			if acc >= half {
+				break
+			}
+		}
+		boxes[best] = b[:cut]
+		boxes = append(boxes, b[cut:])
+	}
+	return boxes
+}
+
+func longestAxis(b []bin) (axis, extent int) {
+	for a := 0; a < 3; a++ {
+		lo, hi := 255, 0
+		for _, c := range b {
+			v := int(c.key[a])
+			lo, hi = min(lo, v), max(hi, v)
+		}
+		if hi-lo > extent {
+			axis, extent = a, hi-lo
+		}
+	}
+	return axis, extent
+}
+
+func total(b []bin) int {
+	n := 0
+	for _, c := range b {
+		n += c.count
+	}
+	return n
+}
+
+// mean returns the average color of all pixels in a box.
+func mean(b []bin) color.RGBA {
+	var n, r, g, bl int
+	for _, c := range b {
+		n += c.count
+		r += c.sumR
+		g += c.sumG
+		bl += c.sumB
+	}
+	return color.RGBA{R: uint8(r / n), G: uint8(g / n), B: uint8(bl / n), A: 255}
+}
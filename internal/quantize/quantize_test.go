@@ -0,0 +1,120 @@
+package quantize
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"genart/internal/core"
+)
+
+func twoColorImage() *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, 8, 8))
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 8; x++ {
+			c := color.RGBA{R: 200, G: 40, B: 40, A: 255}
+			if x < 4 {
+				c = color.RGBA{R: 10, G: 20, B: 120, A: 255}
+			}
+			img.SetRGBA(x, y, c)
+		}
+	}
+	return img
+}
+
+func TestMedianCutFindsImageColors(t *testing.T) {
+	pal := MedianCut([]image.Image{twoColorImage()}, 16, nil)
+	if len(pal) != 2 {
+		t.Fatalf("got %d colors, want 2: %v", len(pal), pal)
+	}
+	for _, want := range []color.RGBA{{R: 10, G: 20, B: 120, A: 255}, {R: 200, G: 40, B: 40, A: 255}} {
+		if pal[pal.Index(want)] != want {
+			t.Errorf("palette %v is missing %v", pal, want)
+		}
+	}
+}
+
+func TestMedianCutKeepsSeedsFirst(t *testing.T) {
+	seeds := []core.RGBA{{G: 1, A: 1}, {A: 1}, {G: 1, A: 1}}
+	pal := MedianCut([]image.Image{twoColorImage()}, 4, seeds)
+	if len(pal) > 4 {
+		t.Fatalf("got %d colors, want at most 4", len(pal))
+	}
+	if pal[0] != (color.RGBA{G: 255, A: 255}) || pal[1] != (color.RGBA{A: 255}) {
+		t.Errorf("seeds not first or not deduplicated: %v", pal[:2])
+	}
+}
+
+func TestMedianCutReservesHistogramSlots(t *testing.T) {
+	// more distinct seeds than palette slots, as an animated palette
+	// gives with a global GIF palette
+	seeds := make([]core.RGBA, 300)
+	for i := range seeds {
+		seeds[i] = core.RGBA{R: float64(i%20) / 19, G: float64(i/20) / 14, B: 1, A: 1}
+	}
+	pal := MedianCut([]image.Image{twoColorImage()}, 256, seeds)
+	if len(pal) > 256 {
+		t.Fatalf("got %d colors, want at most 256", len(pal))
+	}
+	for _, want := range []color.RGBA{{R: 10, G: 20, B: 120, A: 255}, {R: 200, G: 40, B: 40, A: 255}} {
+		found := false
+		for _, c := range pal {
+			found = found || c == want
+		}
+		if !found {
+			t.Errorf("image color %v crowded out by seeds", want)
+		}
+	}
+}
+
+func TestDrawModes(t *testing.T) {
+	src := twoColorImage()
+	pal := MedianCut([]image.Image{src}, 4, nil)
+	for _, mode := range []string{"", DitherNone, DitherFloydSteinberg, DitherBayer} {
+		dst := image.NewPaletted(src.Bounds(), pal)
+		if err := Draw(dst, src, mode); err != nil {
+			t.Fatalf("%q: %v", mode, err)
+		}
+		// exact palette colors survive every mode
+		if dst.At(0, 0) != src.At(0, 0) || dst.At(7, 7) != src.At(7, 7) {
+			t.Errorf("%q: colors changed", mode)
+		}
+	}
+
+	if err := Draw(image.NewPaletted(src.Bounds(), pal), src, "halftone"); err == nil {
+		t.Error("expected error for unknown dither mode")
+	}
+}
+
+func TestExtract(t *testing.T) {
+	img := twoColorImage()
+	// a quarter of the pixels in a third color
+	for y := 0; y < 4; y++ {
+		for x := 4; x < 8; x++ {
+			img.SetRGBA(x, y, color.RGBA{R: 240, G: 230, B: 200, A: 255})
+		}
+	}
+
+	for _, method := range []string{MethodKMeans, MethodMedianCut} {
+		for _, space := range []core.ColorSpace{core.SpaceSRGB, core.SpaceOKLab} {
+			clusters, err := Extract(img, 3, method, space)
+			if err != nil {
+				t.Fatalf("%s/%s: %v", method, space, err)
+			}
+			if len(clusters) != 3 {
+				t.Fatalf("%s/%s: got %d clusters, want 3", method, space, len(clusters))
+			}
+			sum := 0.0
+			for _, c := range clusters {
+				sum += c.Weight
+			}
+			if sum < 0.999 || sum > 1.001 {
+				t.Errorf("%s/%s: weights sum to %g", method, space, sum)
+			}
+		}
+	}
+
+	if _, err := Extract(img, 3, "octree", ""); err == nil {
+		t.Error("expected error for unknown method")
+	}
+}
@@ -0,0 +1,129 @@
+package registry
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"genart/internal/core"
+)
+
+// PaletteFunc generates n colors derived from a base color, stepping
+// through the given space ("" for the generator's default).
+type PaletteFunc func(base core.RGBA, n int, space core.ColorSpace) ([]core.RGBA, error)
+
+// Shared registries. Packages add themselves from init(), so importing
+// a package is enough to make it available by name from a config.
+var (
+	Engines   = New[core.Engine]("engine")
+	Palettes  = New[PaletteFunc]("palette")
+	Renderers = New[core.Renderer]("renderer")
+)
+
+// Registry maps names (and aliases) to values of one kind.
+type Registry[T any] struct {
+	kind    string
+	mu      sync.RWMutex
+	entries map[string]T
+	aliases map[string]string // alias → canonical name
+}
+
+// New creates an empty registry. kind is used in error messages.
+func New[T any](kind string) *Registry[T] {
+	return &Registry[T]{
+		kind:    kind,
+		entries: make(map[string]T),
+		aliases: make(map[string]string),
+	}
+}
+
+// Register adds v under name and any aliases.
+// It panics if a name or alias is already taken, since that can only
+// happen through a programming error at init time.
+func (r *Registry[T]) Register(name string, v T, aliases ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if name == "" {
+		panic(fmt.Sprintf("registry: empty %s name", r.kind))
+	}
+	if r.taken(name) {
+		panic(fmt.Sprintf("registry: duplicate %s %q", r.kind, name))
+	}
+	for _, a := range aliases {
+		if a == name || r.taken(a) {
+			panic(fmt.Sprintf("registry: duplicate %s alias %q", r.kind, a))
+		}
+	}
+
+	r.entries[name] = v
+	for _, a := range aliases {
+		r.aliases[a] = name
+	}
+}
+
+func (r *Registry[T]) taken(name string) bool {
+	_, ok := r.entries[name]
+	_, isAlias := r.aliases[name]
+	return ok || isAlias
+}
+
+// Lookup returns the value registered under name or one of its aliases.
+func (r *Registry[T]) Lookup(name string) (T, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if canonical, ok := r.aliases[name]; ok {
+		name = canonical
+	}
+	v, ok := r.entries[name]
+	if !ok {
+		var zero T
+		return zero, fmt.Errorf("unknown %s %q (available: %s)", r.kind, name, strings.Join(r.names(), ", "))
+	}
+	return v, nil
+}
+
+// Canonical returns the registered name for name or one of its aliases.
+func (r *Registry[T]) Canonical(name string) (string, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if canonical, ok := r.aliases[name]; ok {
+		return canonical, true
+	}
+	_, ok := r.entries[name]
+	return name, ok
+}
+
+// Names returns all canonical names in sorted order.
+func (r *Registry[T]) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.names()
+}
+
+func (r *Registry[T]) names() []string {
+	out := make([]string, 0, len(r.entries))
+	for name := range r.entries {
+		out = append(out, name)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// Aliases returns the aliases registered for a canonical name, sorted.
+func (r *Registry[T]) Aliases(name string) []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	out := make([]string, 0)
+	for a, canonical := range r.aliases {
+		if canonical == name {
+			out = append(out, a)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
@@ -0,0 +1,48 @@
+package registry
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRegisterLookup(t *testing.T) {
+	r := New[int]("number")
+	r.Register("one", 1, "uno")
+	r.Register("two", 2)
+
+	if v, err := r.Lookup("one"); err != nil || v != 1 {
+		t.Fatalf("lookup by name failed: %v %v", v, err)
+	}
+	if v, err := r.Lookup("uno"); err != nil || v != 1 {
+		t.Fatalf("lookup by alias failed: %v %v", v, err)
+	}
+	if _, err := r.Lookup("three"); err == nil {
+		t.Fatalf("expected error for unknown name")
+	}
+	if got := r.Names(); !reflect.DeepEqual(got, []string{"one", "two"}) {
+		t.Errorf("unexpected names %v", got)
+	}
+	if name, ok := r.Canonical("uno"); !ok || name != "one" {
+		t.Errorf("expected canonical name one, got %q", name)
+	}
+}
+
+func TestRegisterDuplicatePanics(t *testing.T) {
+	cases := []func(r *Registry[int]){
+		func(r *Registry[int]) { r.Register("one", 2) },
+		func(r *Registry[int]) { r.Register("other", 2, "uno") },
+		func(r *Registry[int]) { r.Register("uno", 2) },
+	}
+	for i, register := range cases {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("case %d: expected panic on duplicate", i)
+				}
+			}()
+			r := New[int]("number")
+			r.Register("one", 1, "uno")
+			register(r)
+		}()
+	}
+}
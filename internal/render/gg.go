@@ -5,41 +5,51 @@ import (
 	"image"
 
 	"genart/internal/core"
+	"genart/internal/registry"
 
 	"github.com/fogleman/gg"
 )
 
 type GG struct{}
 
+func init() { registry.Renderers.Register(GG{}.Name(), GG{}) }
+
 func (GG) Name() string { return "gg" }
 
 // Render maps logical [0..1] coordinates to pixels, applies margins,
-// and paints fills first, then strokes.
+// and paints items in Scene order. With Supersample > 1 it rasterizes
+// at Width×Supersample by Height×Supersample and downsamples the result
+// with cfg.Filter. With Tile, items crossing an edge are drawn again on
+// the opposite side.
 func (GG) Render(scene core.Scene, cfg core.RenderConfig) (image.Image, error) {
-	W, H := cfg.Width, cfg.Height
-	if W <= 0 || H <= 0 {
+	if cfg.Width <= 0 || cfg.Height <= 0 {
 		return nil, ErrInvalidSize
 	}
+	if err := validFilter(cfg.Filter); err != nil {
+		return nil, err
+	}
+	if cfg.Tile {
+		if cfg.Margin != 0 {
+			return nil, ErrTileMargin
+		}
+		scene = wrapScene(scene)
+	}
 
-	dc := gg.NewContext(W, H)
+	ss := cfg.Supersample
+	if ss < 1 {
+		ss = 1
+	}
+	// margins and line widths scale with the canvas via the viewport
+	W, H := cfg.Width*ss, cfg.Height*ss
+
+	im := image.NewRGBA(image.Rect(0, 0, W, H))
+	dc := gg.NewContextForRGBA(im)
 
 	// Background
 	dc.SetRGBA(cfg.Background.R, cfg.Background.G, cfg.Background.B, cfg.Background.A)
 	dc.Clear()
 
-	// margin in pixels
-	marginPx := cfg.Margin * float64(min(W, H))
-	x0 := marginPx
-	y0 := marginPx
-	x1 := float64(W) - marginPx
-	y1 := float64(H) - marginPx
-	sx := x1 - x0
-	sy := y1 - y0
-	minWH := float64(min(W, H))
-
-	mapPt := func(v core.Vec2) (float64, float64) {
-		return x0 + v.X*sx, y0 + v.Y*sy
-	}
+	vp := newViewport(W, H, cfg.Margin)
 
 	// Render items
 	for _, it := range scene.Items {
@@ -47,7 +57,7 @@ func (GG) Render(scene core.Scene, cfg core.RenderConfig) (image.Image, error) {
 		case core.Fill:
 			dc.NewSubPath()
 			for i, p := range s.Polygon.Points {
-				x, y := mapPt(p)
+				x, y := vp.mapPt(p)
 				if i == 0 {
 					dc.MoveTo(x, y)
 				} else {
@@ -63,7 +73,7 @@ func (GG) Render(scene core.Scene, cfg core.RenderConfig) (image.Image, error) {
 		case core.Stroke:
 			dc.NewSubPath()
 			for i, p := range s.Path.Points {
-				x, y := mapPt(p)
+				x, y := vp.mapPt(p)
 				if i == 0 {
 					dc.MoveTo(x, y)
 				} else {
@@ -74,12 +84,12 @@ func (GG) Render(scene core.Scene, cfg core.RenderConfig) (image.Image, error) {
 				dc.ClosePath()
 			}
 			dc.SetRGBA(s.Color.R, s.Color.G, s.Color.B, s.Alpha)
-			dc.SetLineWidth(s.Width * minWH) // logical → pixels
+			dc.SetLineWidth(vp.lineWidth(s.Width)) // logical → pixels
 			dc.Stroke()
 		}
 	}
 
-	return dc.Image(), nil
+	return downsample(im, ss, cfg.Filter), nil
 }
 
 // simple helper
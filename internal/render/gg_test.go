@@ -0,0 +1,93 @@
+package render
+
+import (
+	"image"
+	"math"
+	"testing"
+
+	"genart/internal/core"
+)
+
+func TestGGSupersampleSize(t *testing.T) {
+	for _, filter := range []string{FilterBox, FilterLanczos} {
+		img, err := GG{}.Render(core.Scene{}, core.RenderConfig{
+			Width:       40,
+			Height:      30,
+			Background:  core.RGBA{R: 0.2, G: 0.4, B: 0.6, A: 1},
+			Supersample: 4,
+			Filter:      filter,
+		})
+		if err != nil {
+			t.Fatalf("%s: render failed: %v", filter, err)
+		}
+		if b := img.Bounds(); b.Dx() != 40 || b.Dy() != 30 {
+			t.Fatalf("%s: expected 40x30, got %dx%d", filter, b.Dx(), b.Dy())
+		}
+
+		// a flat background must survive downsampling unchanged
+		want := img.(*image.RGBA).RGBAAt(0, 0)
+		got := img.(*image.RGBA).RGBAAt(20, 15)
+		if got != want {
+			t.Errorf("%s: flat background changed: %v vs %v", filter, got, want)
+		}
+	}
+}
+
+func TestDownsampleBoxAverages(t *testing.T) {
+	// 2x2 checkerboard of black/white averages to mid gray
+	src := image.NewRGBA(image.Rect(0, 0, 2, 2))
+	for i := range src.Pix {
+		src.Pix[i] = 255
+	}
+	for _, p := range []image.Point{{0, 0}, {1, 1}} {
+		i := src.PixOffset(p.X, p.Y)
+		src.Pix[i], src.Pix[i+1], src.Pix[i+2] = 0, 0, 0
+	}
+
+	dst := downsample(src, 2, FilterBox)
+	c := dst.RGBAAt(0, 0)
+	if c.R != 128 || c.A != 255 {
+		t.Fatalf("expected mid gray, got %v", c)
+	}
+}
+
+func TestUnknownFilter(t *testing.T) {
+	_, err := GG{}.Render(core.Scene{}, core.RenderConfig{Width: 1, Height: 1, Filter: "nope"})
+	if err == nil {
+		t.Fatal("expected error for unknown filter")
+	}
+}
+
+func TestWrapSceneCopiesEdgeItems(t *testing.T) {
+	stroke := func(x0, y0, x1, y1 float64) core.Stroke {
+		return core.Stroke{Path: core.Path{Points: []core.Vec2{{X: x0, Y: y0}, {X: x1, Y: y1}}}, Width: 0.01}
+	}
+	scene := core.Scene{Items: []core.Item{
+		stroke(0.4, 0.4, 0.6, 0.6),     // inside: no copies
+		stroke(0.9, 0.5, 1.1, 0.5),     // right edge: one copy
+		stroke(0.95, 0.95, 1.05, 1.05), // corner: three copies
+	}}
+	out := wrapScene(scene)
+	if len(out.Items) != 3+1+3 {
+		t.Fatalf("got %d items, want 7", len(out.Items))
+	}
+	if p := out.Items[2].(core.Stroke).Path.Points[0]; math.Abs(p.X+0.1) > 1e-9 || p.Y != 0.5 {
+		t.Errorf("edge copy starts at %v, want (-0.1, 0.5)", p)
+	}
+
+	if _, err := (GG{}).Render(scene, core.RenderConfig{Width: 10, Height: 10, Margin: 0.1, Tile: true}); err != ErrTileMargin {
+		t.Errorf("tiling with a margin: got %v, want ErrTileMargin", err)
+	}
+}
+
+func TestTileImage(t *testing.T) {
+	src := image.NewRGBA(image.Rect(0, 0, 3, 2))
+	src.Pix[0] = 200
+	out := TileImage(src, 2, 2)
+	if b := out.Bounds(); b.Dx() != 6 || b.Dy() != 4 {
+		t.Fatalf("got %v, want 6x4", b)
+	}
+	if out.RGBAAt(3, 2).R != 200 || out.RGBAAt(1, 0).R != 0 {
+		t.Errorf("tiles not repeated")
+	}
+}
@@ -0,0 +1,174 @@
+package render
+
+import (
+	"fmt"
+	"image"
+	"math"
+)
+
+// Downsampling filters for supersampled rendering.
+const (
+	FilterBox     = "box"
+	FilterLanczos = "lanczos"
+)
+
+// lanczosA is the Lanczos window size (Lanczos3).
+const lanczosA = 3.0
+
+// validFilter reports whether name is a known downsampling filter.
+// An empty name selects the box filter.
+func validFilter(name string) error {
+	switch name {
+	case "", FilterBox, FilterLanczos:
+		return nil
+	default:
+		return fmt.Errorf("render: unknown supersample filter %q", name)
+	}
+}
+
+// downsample shrinks src by an integer factor with the named filter.
+// src holds premultiplied colors, so both filters average them directly.
+func downsample(src *image.RGBA, factor int, filter string) *image.RGBA {
+	if factor <= 1 {
+		return src
+	}
+	if filter == FilterLanczos {
+		return resampleSeparable(src, factor, lanczosWeights(factor))
+	}
+	return resampleSeparable(src, factor, boxWeights(factor))
+}
+
+// kernel holds the taps for one output pixel: source offsets relative
+// to i*factor and their normalized weights.
+type kernel struct {
+	offsets []int
+	weights []float64
+}
+
+// boxWeights averages the factor×factor block under each output pixel.
+func boxWeights(factor int) kernel {
+	k := kernel{}
+	w := 1 / float64(factor)
+	for j := 0; j < factor; j++ {
+		k.offsets = append(k.offsets, j)
+		k.weights = append(k.weights, w)
+	}
+	return k
+}
+
+// lanczosWeights builds a Lanczos3 kernel stretched by factor so it
+// low-pass filters at the output resolution.
+func lanczosWeights(factor int) kernel {
+	k := kernel{}
+	f := float64(factor)
+	// output pixel center in source coordinates, relative to i*factor
+	center := 0.5*f - 0.5
+	support := int(math.Ceil(lanczosA * f))
+
+	sum := 0.0
+	for j := -support; j <= factor+support; j++ {
+		w := lanczos((float64(j) - center) / f)
+		if w == 0 {
+			continue
+		}
+		k.offsets = append(k.offsets, j)
+		k.weights = append(k.weights, w)
+		sum += w
+	}
+	for i := range k.weights {
+		k.weights[i] /= sum
+	}
+	return k
+}
+
+func lanczos(x float64) float64 {
+	if x == 0 {
+		return 1
+	}
+	if x <= -lanczosA || x >= lanczosA {
+		return 0
+	}
+	px := math.Pi * x
+	return lanczosA * math.Sin(px) * math.Sin(px/lanczosA) / (px * px)
+}
+
+// resampleSeparable applies k horizontally then vertically, clamping
+// taps at the image edges.
+func resampleSeparable(src *image.RGBA, factor int, k kernel) *image.RGBA {
+	sb := src.Bounds()
+	sw, sh := sb.Dx(), sb.Dy()
+	dw, dh := sw/factor, sh/factor
+
+	// horizontal pass: sw×sh → dw×sh, kept in float to avoid double rounding
+	tmp := make([]float64, dw*sh*4)
+	for y := 0; y < sh; y++ {
+		row := src.Pix[y*src.Stride:]
+		for x := 0; x < dw; x++ {
+			var acc [4]float64
+			for t, off := range k.offsets {
+				sx := clampInt(x*factor+off, 0, sw-1)
+				w := k.weights[t]
+				p := row[sx*4 : sx*4+4]
+				acc[0] += w * float64(p[0])
+				acc[1] += w * float64(p[1])
+				acc[2] += w * float64(p[2])
+				acc[3] += w * float64(p[3])
+			}
+			copy(tmp[(y*dw+x)*4:], acc[:])
+		}
+	}
+
+	// vertical pass: dw×sh → dw×dh
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < dh; y++ {
+		for x := 0; x < dw; x++ {
+			var acc [4]float64
+			for t, off := range k.offsets {
+				sy := clampInt(y*factor+off, 0, sh-1)
+				w := k.weights[t]
+				p := tmp[(sy*dw+x)*4:]
+				acc[0] += w * p[0]
+				acc[1] += w * p[1]
+				acc[2] += w * p[2]
+				acc[3] += w * p[3]
+			}
+			// premultiplied: color channels may not exceed alpha
+			a := clampByte(acc[3])
+			i := dst.PixOffset(x, y)
+			dst.Pix[i+0] = clampByteMax(acc[0], a)
+			dst.Pix[i+1] = clampByteMax(acc[1], a)
+			dst.Pix[i+2] = clampByteMax(acc[2], a)
+			dst.Pix[i+3] = a
+		}
+	}
+	return dst
+}
+
+func clampInt(v, lo, hi int) int {
+	if v < lo {
+		return lo
+	}
+	if v > hi {
+		return hi
+	}
+	return v
+}
+
+func clampByte(v float64) uint8 {
+	v = math.Round(v)
+	if v < 0 {
+		return 0
+	}
+	if v > 255 {
+		return 255
+	}
+	return uint8(v)
+}
+
+func clampByteMax(v float64, hi uint8) uint8 {
+	b := clampByte(v)
+	if b > hi {
+		return hi
+	}
+	return b
+}
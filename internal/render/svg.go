@@ -0,0 +1,120 @@
+package render
+
+import (
+	"bufio"
+	"fmt"
+	"image"
+	"io"
+	"math"
+	"strconv"
+
+	"genart/internal/core"
+	"genart/internal/registry"
+)
+
+// SVG writes a Scene as an SVG document. Width and Height become the
+// document's user units, so the output scales to any print size.
+type SVG struct{}
+
+func init() { registry.Renderers.Register(SVG{}.Name(), SVG{}) }
+
+func (SVG) Name() string { return "svg" }
+
+// Render rasterizes the Scene through GG. It exists so SVG can be used
+// anywhere a core.Renderer is expected (e.g. animation frames).
+func (SVG) Render(scene core.Scene, cfg core.RenderConfig) (image.Image, error) {
+	return GG{}.Render(scene, cfg)
+}
+
+// Encode maps logical [0..1] coordinates to document units, applies
+// margins exactly like GG, and writes items in Scene order.
+func (SVG) Encode(w io.Writer, scene core.Scene, cfg core.RenderConfig) error {
+	W, H := cfg.Width, cfg.Height
+	if W <= 0 || H <= 0 {
+		return ErrInvalidSize
+	}
+	if cfg.Tile {
+		if cfg.Margin != 0 {
+			return ErrTileMargin
+		}
+		scene = wrapScene(scene)
+	}
+
+	vp := newViewport(W, H, cfg.Margin)
+	bw := bufio.NewWriter(w)
+
+	fmt.Fprintf(bw, `<?xml version="1.0" encoding="UTF-8"?>`+"\n")
+	fmt.Fprintf(bw, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`+"\n", W, H, W, H)
+
+	// Background
+	bg := cfg.Background
+	fmt.Fprintf(bw, `<rect width="100%%" height="100%%" fill="%s" fill-opacity="%s"/>`+"\n", svgColor(bg), fmtFloat(bg.A))
+
+	// Render items
+	for _, it := range scene.Items {
+		switch s := it.(type) {
+		case core.Fill:
+			d := svgPath(vp, s.Polygon)
+			if d == "" {
+				continue
+			}
+			fmt.Fprintf(bw, `<path d="%s" fill="%s" fill-opacity="%s" stroke="none"/>`+"\n",
+				d, svgColor(s.Color), fmtFloat(s.Alpha))
+
+		case core.Stroke:
+			d := svgPath(vp, s.Path)
+			if d == "" {
+				continue
+			}
+			// gg strokes with round caps and joins by default
+			fmt.Fprintf(bw, `<path d="%s" fill="none" stroke="%s" stroke-opacity="%s" stroke-width="%s" stroke-linecap="round" stroke-linejoin="round"/>`+"\n",
+				d, svgColor(s.Color), fmtFloat(s.Alpha), fmtFloat(vp.lineWidth(s.Width)))
+		}
+	}
+
+	fmt.Fprintln(bw, "</svg>")
+	return bw.Flush()
+}
+
+// --- helpers ---
+
+// svgPath builds the "d" attribute for a path in document units.
+func svgPath(vp viewport, p core.Path) string {
+	if len(p.Points) == 0 {
+		return ""
+	}
+	buf := make([]byte, 0, len(p.Points)*16)
+	for i, pt := range p.Points {
+		x, y := vp.mapPt(pt)
+		if i == 0 {
+			buf = append(buf, 'M')
+		} else {
+			buf = append(buf, ' ', 'L')
+		}
+		buf = strconv.AppendFloat(buf, round3(x), 'f', -1, 64)
+		buf = append(buf, ' ')
+		buf = strconv.AppendFloat(buf, round3(y), 'f', -1, 64)
+	}
+	if p.Closed {
+		buf = append(buf, ' ', 'Z')
+	}
+	return string(buf)
+}
+
+// svgColor encodes the RGB part of a color as rgb(r,g,b).
+func svgColor(c core.RGBA) string {
+	return fmt.Sprintf("rgb(%d,%d,%d)", to8(c.R), to8(c.G), to8(c.B))
+}
+
+func to8(v float64) int {
+	return int(math.Round(math.Max(0, math.Min(1, v)) * 255))
+}
+
+// round3 keeps documents compact; 1/1000 of a unit is far below print resolution.
+func round3(v float64) float64 {
+	return math.Round(v*1000) / 1000
+}
+
+func fmtFloat(v float64) string {
+	return strconv.FormatFloat(round3(v), 'f', -1, 64)
+}
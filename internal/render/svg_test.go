@@ -0,0 +1,55 @@
+package render
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+
+	"genart/internal/core"
+)
+
+func TestSVGEncode(t *testing.T) {
+	scene := core.Scene{}
+	pts := []core.Vec2{{X: 0, Y: 0}, {X: 1, Y: 0}, {X: 1, Y: 1}}
+	scene.AddFill(pts, core.RGBA{R: 1, G: 0, B: 0, A: 1}, 0.5)
+	scene.AddStroke(pts, false, 0.01, core.RGBA{R: 0, G: 0, B: 1, A: 1}, 1)
+
+	var buf bytes.Buffer
+	err := SVG{}.Encode(&buf, scene, core.RenderConfig{
+		Width:  200,
+		Height: 100,
+		Margin: 0.1,
+	})
+	if err != nil {
+		t.Fatalf("encode failed: %v", err)
+	}
+
+	// must be well-formed XML
+	dec := xml.NewDecoder(bytes.NewReader(buf.Bytes()))
+	for {
+		if _, err := dec.Token(); err != nil {
+			if err != io.EOF {
+				t.Fatalf("invalid XML: %v", err)
+			}
+			break
+		}
+	}
+
+	out := buf.String()
+	// margin = 0.1 * min(200,100) = 10 → (0,0) maps to (10,10), (1,1) to (190,90)
+	if !strings.Contains(out, `d="M10 10 L190 10 L190 90 Z"`) {
+		t.Errorf("fill path not mapped like GG:\n%s", out)
+	}
+	if !strings.Contains(out, `stroke-width="1"`) {
+		t.Errorf("expected stroke width of 1 unit:\n%s", out)
+	}
+}
+
+func TestSVGInvalidSize(t *testing.T) {
+	var buf bytes.Buffer
+	if err := (SVG{}).Encode(&buf, core.Scene{}, core.RenderConfig{}); err != ErrInvalidSize {
+		t.Fatalf("expected ErrInvalidSize, got %v", err)
+	}
+}
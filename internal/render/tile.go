@@ -0,0 +1,90 @@
+package render
+
+import (
+	"errors"
+	"image"
+	"image/draw"
+	"math"
+
+	"genart/internal/core"
+)
+
+var ErrTileMargin = errors.New("render: tiling requires margin 0")
+
+// wrapScene returns scene plus, for every item reaching past an edge of
+// the [0,1] canvas, copies shifted by whole canvases onto the opposite
+// side, so the rendered image repeats without seams. Copies follow
+// their original to keep the paint order.
+func wrapScene(scene core.Scene) core.Scene {
+	out := core.Scene{Items: make([]core.Item, 0, len(scene.Items))}
+	for _, it := range scene.Items {
+		out.Items = append(out.Items, it)
+
+		var p core.Path
+		pad := 0.0
+		switch s := it.(type) {
+		case core.Stroke:
+			p, pad = s.Path, s.Width/2
+		case core.Fill:
+			p = s.Polygon
+		}
+		if len(p.Points) == 0 {
+			continue
+		}
+		lo, hi := bounds(p.Points)
+		for oy := -1.0; oy <= 1; oy++ {
+			for ox := -1.0; ox <= 1; ox++ {
+				if ox == 0 && oy == 0 {
+					continue
+				}
+				if hi.X+pad+ox < 0 || lo.X-pad+ox > 1 || hi.Y+pad+oy < 0 || lo.Y-pad+oy > 1 {
+					continue
+				}
+				out.Items = append(out.Items, shifted(it, core.Vec2{X: ox, Y: oy}))
+			}
+		}
+	}
+	return out
+}
+
+func bounds(pts []core.Vec2) (lo, hi core.Vec2) {
+	lo, hi = pts[0], pts[0]
+	for _, p := range pts[1:] {
+		lo.X, lo.Y = math.Min(lo.X, p.X), math.Min(lo.Y, p.Y)
+		hi.X, hi.Y = math.Max(hi.X, p.X), math.Max(hi.Y, p.Y)
+	}
+	return lo, hi
+}
+
+func shifted(it core.Item, d core.Vec2) core.Item {
+	move := func(p core.Path) core.Path {
+		pts := make([]core.Vec2, len(p.Points))
+		for i, q := range p.Points {
+			pts[i] = core.Vec2{X: q.X + d.X, Y: q.Y + d.Y}
+		}
+		return core.Path{Points: pts, Closed: p.Closed}
+	}
+	switch s := it.(type) {
+	case core.Stroke:
+		s.Path = move(s.Path)
+		return s
+	case core.Fill:
+		s.Polygon = move(s.Polygon)
+		return s
+	}
+	return it
+}
+
+// TileImage repeats img nx by ny times, to check that a tile's edges
+// meet seamlessly.
+func TileImage(img image.Image, nx, ny int) *image.RGBA {
+	b := img.Bounds()
+	out := image.NewRGBA(image.Rect(0, 0, b.Dx()*nx, b.Dy()*ny))
+	for j := 0; j < ny; j++ {
+		for i := 0; i < nx; i++ {
+			r := image.Rect(i*b.Dx(), j*b.Dy(), (i+1)*b.Dx(), (j+1)*b.Dy())
+			draw.Draw(out, r, img, b.Min, draw.Src)
+		}
+	}
+	return out
+}
@@ -0,0 +1,41 @@
+package render
+
+import "genart/internal/core"
+
+// viewport maps logical [0..1] coordinates onto a W×H output area,
+// applying the configured margin. Every backend shares it so that
+// a Scene lands in exactly the same place regardless of format.
+type viewport struct {
+	x0, y0 float64
+	sx, sy float64
+	minWH  float64
+}
+
+func newViewport(W, H int, margin float64) viewport {
+	minWH := float64(min(W, H))
+
+	// margin in output units
+	marginPx := margin * minWH
+	x0 := marginPx
+	y0 := marginPx
+	x1 := float64(W) - marginPx
+	y1 := float64(H) - marginPx
+
+	return viewport{
+		x0:    x0,
+		y0:    y0,
+		sx:    x1 - x0,
+		sy:    y1 - y0,
+		minWH: minWH,
+	}
+}
+
+// mapPt converts a logical point to output coordinates.
+func (v viewport) mapPt(p core.Vec2) (float64, float64) {
+	return v.x0 + p.X*v.sx, v.y0 + p.Y*v.sy
+}
+
+// lineWidth converts a logical width (fraction of min(W,H)) to output units.
+func (v viewport) lineWidth(w float64) float64 {
+	return w * v.minWH
+}